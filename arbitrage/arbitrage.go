@@ -0,0 +1,143 @@
+// Package arbitrage scans CoinCap markets for price cycles that end with more
+// of the starting asset than they began with. Markets only carry the last
+// traded price, so opportunities are hints to investigate rather than
+// executable quotes.
+package arbitrage
+
+import (
+	"sort"
+
+	"github.com/esenmx/coincap-go"
+)
+
+type Config struct {
+	Threshold        float64            // minimum profit after fees as a fraction, 0.001 is 0.1%
+	Fees             map[string]float64 // trading fee per exchange id as a fraction
+	DefaultFee       float64            // fee of exchanges missing from Fees
+	TransferFee      float64            // cost of moving funds between exchanges as a fraction
+	MaxLegs          int                // optional, trades per cycle, defaults to 3
+	MinVolumeUsd24Hr float64            // optional, ignore markets trading less
+	CrossExchange    bool               // also find cycles spanning several exchanges
+}
+
+func (cfg Config) fee(exchangeId string) float64 {
+	if fee, ok := cfg.Fees[exchangeId]; ok {
+		return fee
+	}
+	return cfg.DefaultFee
+}
+
+// Leg converts From into To at Rate on the exchange of Market.
+type Leg struct {
+	Market coincap.Market
+	From   string  // asset id sold
+	To     string  // asset id bought
+	Rate   float64 // units of To per unit of From before fees
+	Fee    float64 // fraction of the proceeds paid to the exchange
+}
+
+type Opportunity struct {
+	Legs      []Leg
+	Exchanges []string // in order of first use
+	Transfers int      // moves between exchanges, each charged TransferFee
+	Profit    float64  // fraction after fees, 0.01 is 1%
+	// MinVolumeUsd24Hr is the smallest market volume along the cycle, a hint
+	// of how much size the cycle can absorb.
+	MinVolumeUsd24Hr float64
+}
+
+func (o Opportunity) CrossExchange() bool { return len(o.Exchanges) > 1 }
+
+// Scan treats assets as nodes and every market as a pair of edges, selling
+// the base at PriceQuote and buying it at 1/PriceQuote. It returns every
+// simple cycle of up to MaxLegs trades whose profit reaches Threshold,
+// most profitable first.
+func Scan(markets []coincap.Market, cfg Config) []Opportunity {
+	if cfg.MaxLegs == 0 {
+		cfg.MaxLegs = 3
+	}
+	edges := make(map[string][]Leg)
+	for _, m := range markets {
+		if m.PriceQuote <= 0 || m.BaseId == "" || m.QuoteId == "" || m.VolumeUsd24Hr < cfg.MinVolumeUsd24Hr {
+			continue
+		}
+		fee := cfg.fee(m.ExchangeId)
+		edges[m.BaseId] = append(edges[m.BaseId], Leg{Market: m, From: m.BaseId, To: m.QuoteId, Rate: m.PriceQuote, Fee: fee})
+		edges[m.QuoteId] = append(edges[m.QuoteId], Leg{Market: m, From: m.QuoteId, To: m.BaseId, Rate: 1 / m.PriceQuote, Fee: fee})
+	}
+	s := scanner{cfg: cfg, edges: edges, visited: make(map[string]bool)}
+	starts := make([]string, 0, len(edges))
+	for id := range edges {
+		starts = append(starts, id)
+	}
+	sort.Strings(starts)
+	for _, start := range starts {
+		s.walk(start, start, nil)
+	}
+	sort.SliceStable(s.found, func(i, j int) bool { return s.found[i].Profit > s.found[j].Profit })
+	return s.found
+}
+
+type scanner struct {
+	cfg     Config
+	edges   map[string][]Leg
+	visited map[string]bool
+	found   []Opportunity
+}
+
+// walk only visits assets ordered after start, so every cycle is found once
+// from its smallest asset id.
+func (s *scanner) walk(start, at string, path []Leg) {
+	s.visited[at] = true
+	defer delete(s.visited, at)
+	for _, leg := range s.edges[at] {
+		if !s.cfg.CrossExchange && len(path) > 0 && leg.Market.ExchangeId != path[0].Market.ExchangeId {
+			continue
+		}
+		legs := append(path[:len(path):len(path)], leg)
+		if leg.To == start {
+			// trading a pair back and forth on one exchange always loses the fees
+			if len(legs) == 2 && legs[0].Market.ExchangeId == legs[1].Market.ExchangeId {
+				continue
+			}
+			if len(legs) >= 2 {
+				s.evaluate(legs)
+			}
+			continue
+		}
+		if leg.To > start && !s.visited[leg.To] && len(legs) < s.cfg.MaxLegs {
+			s.walk(start, leg.To, legs)
+		}
+	}
+}
+
+func (s *scanner) evaluate(legs []Leg) {
+	o := Opportunity{Legs: legs, MinVolumeUsd24Hr: legs[0].Market.VolumeUsd24Hr}
+	value := 1.0
+	for i, leg := range legs {
+		value *= leg.Rate * (1 - leg.Fee)
+		if next := legs[(i+1)%len(legs)]; next.Market.ExchangeId != leg.Market.ExchangeId {
+			o.Transfers++
+			value *= 1 - s.cfg.TransferFee
+		}
+		if !contains(o.Exchanges, leg.Market.ExchangeId) {
+			o.Exchanges = append(o.Exchanges, leg.Market.ExchangeId)
+		}
+		if leg.Market.VolumeUsd24Hr < o.MinVolumeUsd24Hr {
+			o.MinVolumeUsd24Hr = leg.Market.VolumeUsd24Hr
+		}
+	}
+	o.Profit = value - 1
+	if o.Profit >= s.cfg.Threshold {
+		s.found = append(s.found, o)
+	}
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package arbitrage
+
+import (
+	"testing"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+func market(exchange, base, quote string, price, volume float64) coincap.Market {
+	return coincap.Market{ExchangeId: exchange, BaseId: base, QuoteId: quote, PriceQuote: price, VolumeUsd24Hr: volume}
+}
+
+// binance misprices ethereum/bitcoin by 10% and kraken quotes bitcoin 2%
+// above binance.
+var markets = []coincap.Market{
+	market("binance", "bitcoin", "tether", 100, 5e6),
+	market("binance", "ethereum", "tether", 10, 2e6),
+	market("binance", "ethereum", "bitcoin", 0.11, 1e5),
+	market("kraken", "bitcoin", "tether", 102, 1e6),
+	market("kraken", "dogecoin", "tether", 0, 1e6),
+}
+
+func TestScan_Triangular(t *testing.T) {
+	found := Scan(markets, Config{DefaultFee: 0.001})
+	require.Len(t, found, 1)
+	o := found[0]
+	require.False(t, o.CrossExchange())
+	require.Equal(t, []string{"binance"}, o.Exchanges)
+	require.Zero(t, o.Transfers)
+	require.InDelta(t, 1.1*0.999*0.999*0.999-1, o.Profit, 1e-12)
+	require.Equal(t, 1e5, o.MinVolumeUsd24Hr)
+
+	path := []string{o.Legs[0].From}
+	for _, leg := range o.Legs {
+		path = append(path, leg.To)
+	}
+	require.Equal(t, []string{"bitcoin", "tether", "ethereum", "bitcoin"}, path)
+
+	require.Empty(t, Scan(markets, Config{DefaultFee: 0.04}))
+	require.Empty(t, Scan(markets, Config{DefaultFee: 0.001, Threshold: 0.1}))
+	require.Empty(t, Scan(markets, Config{DefaultFee: 0.001, MinVolumeUsd24Hr: 1e6}))
+	require.Empty(t, Scan(markets, Config{MaxLegs: 2}))
+}
+
+func TestScan_CrossExchange(t *testing.T) {
+	cfg := Config{Fees: map[string]float64{"kraken": 0.002}, DefaultFee: 0.001, TransferFee: 0.0005, CrossExchange: true, Threshold: 0.005}
+	found := Scan(markets, cfg)
+	require.Len(t, found, 3)
+	require.Equal(t, []string{"binance"}, found[1].Exchanges)
+
+	var cross []Opportunity
+	for _, o := range found {
+		if o.CrossExchange() {
+			cross = append(cross, o)
+		}
+	}
+	require.Len(t, cross, 2)
+	// buy bitcoin on binance, sell on kraken
+	simple := cross[1]
+	require.Len(t, simple.Legs, 2)
+	require.Equal(t, 2, simple.Transfers)
+	require.Equal(t, []string{"kraken", "binance"}, simple.Exchanges)
+	require.InDelta(t, 1.02*0.999*0.998*0.9995*0.9995-1, simple.Profit, 1e-12)
+	require.Equal(t, 1e6, simple.MinVolumeUsd24Hr)
+}
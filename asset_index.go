@@ -0,0 +1,208 @@
+package coincap
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AssetIndex resolves ids and ticker symbols to assets. Many assets share a
+// symbol, candidates are ranked by Rank and then by MarketCapUsd.
+type AssetIndex struct {
+	mu        sync.RWMutex
+	byId      map[string]Asset
+	bySymbol  map[string][]string
+	timestamp int64
+}
+
+func NewAssetIndex(assets ...Asset) *AssetIndex {
+	x := &AssetIndex{byId: map[string]Asset{}, bySymbol: map[string][]string{}}
+	x.Add(assets...)
+	return x
+}
+
+// BuildAssetIndex pages through GetAssets until the listing is exhausted.
+func BuildAssetIndex(api Api) (*AssetIndex, error) {
+	x := NewAssetIndex()
+	return x, x.Refresh(api)
+}
+
+// LoadAssetIndex reads an index persisted with Save.
+func LoadAssetIndex(r io.Reader) (*AssetIndex, error) {
+	var data AssetsData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+	x := NewAssetIndex(data.Data...)
+	x.timestamp = data.Timestamp
+	return x, nil
+}
+
+// Save persists the index in the AssetsData response format.
+func (x *AssetIndex) Save(w io.Writer) error {
+	x.mu.RLock()
+	data := AssetsData{Data: make([]Asset, 0, len(x.byId)), Timestamp: x.timestamp}
+	for _, a := range x.byId {
+		data.Data = append(data.Data, a)
+	}
+	x.mu.RUnlock()
+	sortAssets(data.Data)
+	return json.NewEncoder(w).Encode(data)
+}
+
+// Add inserts or replaces assets by id.
+func (x *AssetIndex) Add(assets ...Asset) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.add(assets)
+}
+
+func (x *AssetIndex) add(assets []Asset) {
+	touched := map[string]struct{}{}
+	for _, a := range assets {
+		id := strings.ToLower(a.Id)
+		if old, ok := x.byId[id]; ok {
+			x.removeSymbol(old)
+			touched[strings.ToLower(old.Symbol)] = struct{}{}
+		}
+		x.byId[id] = a
+		symbol := strings.ToLower(a.Symbol)
+		x.bySymbol[symbol] = append(x.bySymbol[symbol], id)
+		touched[symbol] = struct{}{}
+	}
+	for symbol := range touched {
+		x.rankSymbol(symbol)
+	}
+}
+
+// Refresh updates the given ids, or replaces the index with a new listing of
+// every asset when no id is given, dropping delisted assets.
+func (x *AssetIndex) Refresh(api Api, ids ...string) error {
+	var assets []Asset
+	var timestamp int64
+	if len(ids) == 0 {
+		var err error
+		assets, err = Paginate(func(p LimitOffsetParams) ([]Asset, error) {
+			page, err := api.GetAssets(GetAssetsParams{LimitOffsetParams: p})
+			timestamp = page.Timestamp
+			return page.Data, err
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		for i := 0; i < len(ids); i += MaxLimit {
+			end := i + MaxLimit
+			if end > len(ids) {
+				end = len(ids)
+			}
+			page, err := api.GetAssets(GetAssetsParams{Ids: ids[i:end], LimitOffsetParams: LimitOffsetParams{Limit: end - i}})
+			if err != nil {
+				return err
+			}
+			assets, timestamp = append(assets, page.Data...), page.Timestamp
+		}
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if len(ids) == 0 {
+		x.byId, x.bySymbol = map[string]Asset{}, map[string][]string{}
+	}
+	x.add(assets)
+	x.timestamp = timestamp
+	return nil
+}
+
+// Asset looks up an asset by id, case-insensitively.
+func (x *AssetIndex) Asset(id string) (Asset, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	a, ok := x.byId[strings.ToLower(id)]
+	return a, ok
+}
+
+// Resolve returns every asset with the given symbol, best ranked first.
+func (x *AssetIndex) Resolve(symbol string) []Asset {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	ids := x.bySymbol[strings.ToLower(symbol)]
+	assets := make([]Asset, len(ids))
+	for i, id := range ids {
+		assets[i] = x.byId[id]
+	}
+	return assets
+}
+
+// ResolveOne returns the best ranked asset for a symbol.
+func (x *AssetIndex) ResolveOne(symbol string) (Asset, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	ids := x.bySymbol[strings.ToLower(symbol)]
+	if len(ids) == 0 {
+		return Asset{}, false
+	}
+	return x.byId[ids[0]], true
+}
+
+// Lookup resolves a query as an id first, then as a symbol.
+func (x *AssetIndex) Lookup(query string) (Asset, bool) {
+	if a, ok := x.Asset(query); ok {
+		return a, true
+	}
+	return x.ResolveOne(query)
+}
+
+func (x *AssetIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.byId)
+}
+
+// Timestamp is the response timestamp of the latest refresh, in UNIX milliseconds.
+func (x *AssetIndex) Timestamp() int64 {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.timestamp
+}
+
+func (x *AssetIndex) removeSymbol(a Asset) {
+	symbol := strings.ToLower(a.Symbol)
+	id := strings.ToLower(a.Id)
+	ids := x.bySymbol[symbol]
+	for i, v := range ids {
+		if v == id {
+			ids = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(x.bySymbol, symbol)
+	} else {
+		x.bySymbol[symbol] = ids
+	}
+}
+
+func (x *AssetIndex) rankSymbol(symbol string) {
+	ids := x.bySymbol[symbol]
+	sort.SliceStable(ids, func(i, j int) bool { return assetLess(x.byId[ids[i]], x.byId[ids[j]]) })
+}
+
+func sortAssets(assets []Asset) {
+	sort.SliceStable(assets, func(i, j int) bool { return assetLess(assets[i], assets[j]) })
+}
+
+// assetLess orders by Rank, unranked assets last, then by MarketCapUsd descending.
+func assetLess(a, b Asset) bool {
+	if a.Rank != b.Rank {
+		if a.Rank == 0 || b.Rank == 0 {
+			return b.Rank == 0
+		}
+		return a.Rank < b.Rank
+	}
+	if a.MarketCapUsd != b.MarketCapUsd {
+		return a.MarketCapUsd > b.MarketCapUsd
+	}
+	return a.Id < b.Id
+}
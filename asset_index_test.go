@@ -0,0 +1,109 @@
+package coincap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type assetsPager struct {
+	Api
+	assets []Asset
+	calls  []GetAssetsParams
+}
+
+func (p *assetsPager) GetAssets(params GetAssetsParams) (AssetsData, error) {
+	p.calls = append(p.calls, params)
+	if len(params.Ids) > 0 {
+		var data []Asset
+		for _, a := range p.assets {
+			for _, id := range params.Ids {
+				if a.Id == id {
+					data = append(data, a)
+				}
+			}
+		}
+		return AssetsData{Data: data, Timestamp: 2}, nil
+	}
+	start, end := params.Offset, params.Offset+params.Limit
+	if start > len(p.assets) {
+		start = len(p.assets)
+	}
+	if end > len(p.assets) {
+		end = len(p.assets)
+	}
+	return AssetsData{Data: p.assets[start:end], Timestamp: 1}, nil
+}
+
+var uniAssets = []Asset{
+	{Id: "uniswap", Symbol: "UNI", Rank: 12, MarketCapUsd: 9e9},
+	{Id: "unicorn-token", Symbol: "UNI", Rank: 0, MarketCapUsd: 1e3},
+	{Id: "universe", Symbol: "uni", Rank: 1500, MarketCapUsd: 1e6},
+	{Id: "bitcoin", Symbol: "BTC", Rank: 1, MarketCapUsd: 7e11},
+}
+
+func TestAssetIndex_Resolve(t *testing.T) {
+	x := NewAssetIndex(uniAssets...)
+	candidates := x.Resolve("Uni")
+	require.Len(t, candidates, 3)
+	require.Equal(t, "uniswap", candidates[0].Id)
+	require.Equal(t, "universe", candidates[1].Id)
+	require.Equal(t, "unicorn-token", candidates[2].Id)
+
+	a, ok := x.Asset("BITCOIN")
+	require.True(t, ok)
+	require.Equal(t, "BTC", a.Symbol)
+	a, ok = x.Lookup("btc")
+	require.True(t, ok)
+	require.Equal(t, "bitcoin", a.Id)
+	_, ok = x.ResolveOne("xyz")
+	require.False(t, ok)
+
+	x.Add(Asset{Id: "universe", Symbol: "UNIV", Rank: 1400})
+	require.Len(t, x.Resolve("uni"), 2)
+	require.Len(t, x.Resolve("univ"), 1)
+	require.Equal(t, 4, x.Len())
+}
+
+func TestAssetIndex_Refresh(t *testing.T) {
+	assets := make([]Asset, MaxLimit+3)
+	for i := range assets {
+		id := string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('a'+i/676))
+		assets[i] = Asset{Id: id, Symbol: strings.ToUpper(id), Rank: i + 1}
+	}
+	pager := &assetsPager{assets: assets}
+	x, err := BuildAssetIndex(pager)
+	require.NoError(t, err)
+	require.Len(t, pager.calls, 2)
+	require.Equal(t, MaxLimit, pager.calls[1].Offset)
+	require.Equal(t, len(assets), x.Len())
+	require.Equal(t, int64(1), x.Timestamp())
+
+	pager.assets[0].PriceUsd = 42
+	require.NoError(t, x.Refresh(pager, assets[0].Id))
+	require.Equal(t, []string{assets[0].Id}, pager.calls[2].Ids)
+	a, _ := x.Asset(assets[0].Id)
+	require.Equal(t, 42.0, a.PriceUsd)
+	require.Equal(t, int64(2), x.Timestamp())
+
+	delisted := pager.assets[1]
+	pager.assets = pager.assets[2:]
+	require.NoError(t, x.Refresh(pager))
+	require.Equal(t, len(assets)-2, x.Len())
+	_, ok := x.Asset(delisted.Id)
+	require.False(t, ok)
+	require.NotEmpty(t, delisted.Symbol)
+	require.Empty(t, x.Resolve(delisted.Symbol))
+}
+
+func TestAssetIndex_SaveLoad(t *testing.T) {
+	x := NewAssetIndex(uniAssets...)
+	var buf bytes.Buffer
+	require.NoError(t, x.Save(&buf))
+	y, err := LoadAssetIndex(&buf)
+	require.NoError(t, err)
+	require.Equal(t, x.Resolve("UNI"), y.Resolve("UNI"))
+	require.Equal(t, x.Len(), y.Len())
+}
@@ -0,0 +1,169 @@
+package coincap
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type CandleUpdate struct {
+	Exchange string
+	BaseId   string
+	QuoteId  string
+	Candle
+	Final bool // false while the period is still open
+}
+
+type candleKey struct {
+	exchange, base, quote string
+}
+
+// CandleBuilder aggregates trades into OHLCV candles per exchange and pair.
+// Periods are aligned the same way as GetCandles, in UNIX milliseconds.
+// Trades of an already finalized period are dropped.
+type CandleBuilder struct {
+	interval Interval
+	mu       sync.Mutex
+	open     map[candleKey]*Candle
+	closed   map[candleKey]int64
+}
+
+// NewCandleBuilder fails with InvalidParameterError for an unset or unknown interval.
+func NewCandleBuilder(interval Interval) (*CandleBuilder, error) {
+	if interval.Value() == 0 {
+		return nil, InvalidParameterError
+	}
+	return &CandleBuilder{interval: interval, open: map[candleKey]*Candle{}, closed: map[candleKey]int64{}}, nil
+}
+
+func (b *CandleBuilder) Interval() Interval { return b.interval }
+
+// Period returns the start of the candle containing the UNIX millisecond timestamp.
+func (b *CandleBuilder) Period(timestamp int64) int64 {
+	ms := b.interval.Value().Milliseconds()
+	return timestamp - timestamp%ms
+}
+
+// Add returns the finalized candle of the pair if t starts a new period,
+// followed by the provisional candle t belongs to.
+func (b *CandleBuilder) Add(t Trade) []CandleUpdate {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := candleKey{t.Exchange, t.Base, t.Quote}
+	period := b.Period(t.Timestamp)
+	if last, ok := b.closed[key]; ok && period <= last {
+		return nil
+	}
+	var updates []CandleUpdate
+	c := b.open[key]
+	if c != nil && period < c.Period {
+		return nil
+	}
+	if c != nil && period > c.Period {
+		updates = append(updates, b.finalize(key, c))
+		c = nil
+	}
+	if c == nil {
+		c = &Candle{Open: t.Price, High: t.Price, Low: t.Price, Period: period}
+		b.open[key] = c
+	}
+	if t.Price > c.High {
+		c.High = t.Price
+	}
+	if t.Price < c.Low {
+		c.Low = t.Price
+	}
+	c.Close = t.Price
+	c.Volume += t.Volume
+	return append(updates, newCandleUpdate(key, *c, false))
+}
+
+// Flush finalizes every candle whose period ended by now.
+func (b *CandleBuilder) Flush(now time.Time) []CandleUpdate {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ms := b.interval.Value().Milliseconds()
+	var updates []CandleUpdate
+	for key, c := range b.open {
+		if c.Period+ms <= now.UnixMilli() {
+			updates = append(updates, b.finalize(key, c))
+		}
+	}
+	sort.Slice(updates, func(i, j int) bool { return updateLess(updates[i], updates[j]) })
+	return updates
+}
+
+// Run feeds trades, e.g. TradeStream.Trades(), into the builder and sends
+// every update to out. Open candles are finalized on the wall clock at period
+// close. Returns nil once trades is closed.
+func (b *CandleBuilder) Run(ctx context.Context, trades <-chan Trade, out chan<- CandleUpdate) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	for {
+		if next, ok := b.nextClose(); ok {
+			timer.Reset(time.Until(next))
+		}
+		var updates []CandleUpdate
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t, ok := <-trades:
+			if !ok {
+				return nil
+			}
+			updates = b.Add(t)
+		case now := <-timer.C:
+			updates = b.Flush(now)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		for _, u := range updates {
+			select {
+			case out <- u:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (b *CandleBuilder) nextClose() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var next int64
+	for _, c := range b.open {
+		if next == 0 || c.Period < next {
+			next = c.Period
+		}
+	}
+	if next == 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(next).Add(b.interval.Value()), true
+}
+
+func (b *CandleBuilder) finalize(key candleKey, c *Candle) CandleUpdate {
+	delete(b.open, key)
+	b.closed[key] = c.Period
+	return newCandleUpdate(key, *c, true)
+}
+
+func newCandleUpdate(key candleKey, c Candle, final bool) CandleUpdate {
+	return CandleUpdate{Exchange: key.exchange, BaseId: key.base, QuoteId: key.quote, Candle: c, Final: final}
+}
+
+func updateLess(a, b CandleUpdate) bool {
+	if a.Exchange != b.Exchange {
+		return a.Exchange < b.Exchange
+	}
+	if a.BaseId != b.BaseId {
+		return a.BaseId < b.BaseId
+	}
+	return a.QuoteId < b.QuoteId
+}
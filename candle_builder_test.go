@@ -0,0 +1,69 @@
+package coincap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCandleBuilder_Add(t *testing.T) {
+	_, err := NewCandleBuilder(0)
+	require.ErrorIs(t, err, InvalidParameterError)
+	_, err = NewCandleBuilder(D1 + 1)
+	require.ErrorIs(t, err, InvalidParameterError)
+
+	b, err := NewCandleBuilder(M5)
+	require.NoError(t, err)
+	period := int64(1627281000000) // mock/candles.json period, aligned to m5
+	require.Equal(t, period, b.Period(period+4*60*1000+999))
+
+	trade := func(offset time.Duration, price, volume float64) Trade {
+		return Trade{Exchange: "binance", Base: "polkadot", Quote: "tether", Price: price, Volume: volume, Timestamp: period + offset.Milliseconds()}
+	}
+	u := b.Add(trade(0, 15.169, 1))
+	require.Len(t, u, 1)
+	require.False(t, u[0].Final)
+	b.Add(trade(time.Minute, 15.174, 2))
+	b.Add(trade(time.Minute*2, 15.012, 3))
+	u = b.Add(trade(time.Minute*4, 15.13, 4))
+	require.Equal(t, CandleUpdate{
+		Exchange: "binance", BaseId: "polkadot", QuoteId: "tether",
+		Candle: Candle{Open: 15.169, High: 15.174, Low: 15.012, Close: 15.13, Volume: 10, Period: period},
+	}, u[0])
+
+	u = b.Add(trade(time.Minute*5, 15.2, 1))
+	require.Len(t, u, 2)
+	require.True(t, u[0].Final)
+	require.Equal(t, 15.13, u[0].Close)
+	require.Equal(t, period+5*60*1000, u[1].Period)
+	require.Nil(t, b.Add(trade(time.Minute*3, 1, 1)), "late trade of a finalized period")
+
+	u = b.Flush(time.UnixMilli(period + 10*60*1000))
+	require.Len(t, u, 1)
+	require.True(t, u[0].Final)
+	require.Equal(t, 15.2, u[0].Open)
+	require.Empty(t, b.Flush(time.UnixMilli(period+20*60*1000)))
+}
+
+func TestCandleBuilder_Run(t *testing.T) {
+	b, err := NewCandleBuilder(M1)
+	require.NoError(t, err)
+	trades := make(chan Trade)
+	out := make(chan CandleUpdate, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() { errs <- b.Run(ctx, trades, out) }()
+
+	// a trade of the previous minute is finalized by the clock right away
+	trades <- Trade{Exchange: "binance", Base: "bitcoin", Quote: "tether", Price: 1, Volume: 1, Timestamp: time.Now().Add(-time.Minute).UnixMilli()}
+	require.False(t, (<-out).Final)
+	final := <-out
+	require.True(t, final.Final)
+	require.Equal(t, 1.0, final.Close)
+
+	close(trades)
+	require.NoError(t, <-errs)
+}
@@ -0,0 +1,203 @@
+package coincap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strconv"
+	"sync"
+)
+
+var NoInteractionError = errors.New("no recorded interaction")
+
+// Interaction is a recorded request/response pair. Bodies are stored
+// decompressed, JSON bodies verbatim as in the mock fixtures.
+type Interaction struct {
+	Method   string          `json:"method"`
+	Url      string          `json:"url"`
+	Status   int             `json:"status"`
+	Header   http.Header     `json:"header,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"bodyText,omitempty"` // non-JSON bodies
+}
+
+// Cassette holds interactions, persisted as NDJSON, one interaction per line.
+type Cassette struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	played       map[string]int
+}
+
+func NewCassette(interactions ...Interaction) *Cassette {
+	return &Cassette{interactions: interactions, played: map[string]int{}}
+}
+
+func LoadCassette(r io.Reader) (*Cassette, error) {
+	c := NewCassette()
+	dec := json.NewDecoder(r)
+	for {
+		var i Interaction
+		if err := dec.Decode(&i); err == io.EOF {
+			return c, nil
+		} else if err != nil {
+			return nil, err
+		}
+		c.interactions = append(c.interactions, i)
+	}
+}
+
+func (c *Cassette) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, i := range c.Interactions() {
+		if err := enc.Encode(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+func (c *Cassette) Add(i Interaction) {
+	c.mu.Lock()
+	c.interactions = append(c.interactions, i)
+	c.mu.Unlock()
+}
+
+// AddFixture serves a mock/*.json style file for GET requests of rawUrl. A
+// rawUrl without query matches the path with any query.
+func (c *Cassette) AddFixture(rawUrl, filename string) error {
+	bs, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(bs) {
+		return fmt.Errorf("%s: invalid json", filename)
+	}
+	c.Add(Interaction{Method: http.MethodGet, Url: rawUrl, Status: http.StatusOK, Body: bs})
+	return nil
+}
+
+// next returns the interactions recorded for the request in order, repeating
+// the last one once all have been played.
+func (c *Cassette) next(r *http.Request) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u := r.URL.String()
+	var matches []Interaction
+	for _, i := range c.interactions {
+		if i.Method == r.Method && i.Url == u {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		for _, i := range c.interactions {
+			if iu, err := neturl.Parse(i.Url); err == nil && i.Method == r.Method && len(iu.RawQuery) == 0 &&
+				iu.Scheme == r.URL.Scheme && iu.Host == r.URL.Host && iu.Path == r.URL.Path {
+				matches = append(matches, i)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return Interaction{}, false
+	}
+	key := r.Method + " " + u
+	n := c.played[key]
+	c.played[key] = n + 1
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	return matches[n], true
+}
+
+type recordingTransport struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+// NewRecordingTransport passes requests to next, http.DefaultTransport when
+// nil, and adds every exchange to the cassette.
+func NewRecordingTransport(c *Cassette, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordingTransport{cassette: c, next: next}
+}
+
+func (t *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	reader, err := decodeBody(res)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	header := res.Header.Clone()
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	i := Interaction{Method: r.Method, Url: r.URL.String(), Status: res.StatusCode, Header: header}
+	if json.Valid(body) {
+		i.Body = body
+	} else {
+		i.BodyText = string(body)
+	}
+	t.cassette.Add(i)
+	return i.response(r), nil
+}
+
+type replayingTransport struct {
+	cassette *Cassette
+}
+
+// NewReplayingTransport serves requests from the cassette only, failing with
+// NoInteractionError for unknown requests.
+func NewReplayingTransport(c *Cassette) http.RoundTripper {
+	return &replayingTransport{cassette: c}
+}
+
+func (t *replayingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	i, ok := t.cassette.next(r)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", NoInteractionError, r.Method, r.URL)
+	}
+	return i.response(r), nil
+}
+
+func (i Interaction) response(r *http.Request) *http.Response {
+	body := []byte(i.BodyText)
+	if len(i.Body) > 0 {
+		body = i.Body
+	}
+	header := i.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+		StatusCode:    i.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
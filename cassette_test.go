@@ -0,0 +1,138 @@
+package coincap
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureClient(t *testing.T) *Client {
+	c := NewCassette()
+	for path, fixture := range map[string]string{
+		"/assets":                  "assets",
+		"/assets/polkadot":         "asset_id",
+		"/assets/polkadot/history": "asset_history",
+		"/assets/polkadot/markets": "asset_markets",
+		"/rates":                   "rates",
+		"/rates/usd-coin":          "rates_id",
+		"/exchanges":               "exchanges",
+		"/exchanges/kraken":        "exchange",
+		"/markets":                 "markets",
+		"/candles":                 "candles",
+	} {
+		require.NoError(t, c.AddFixture(url+path, "mock/"+fixture+".json"))
+	}
+	return NewClient(WithHttpClient(&http.Client{Transport: NewReplayingTransport(c)}))
+}
+
+func TestReplayingTransport_Api(t *testing.T) {
+	var api Api = fixtureClient(t)
+	assets, err := api.GetAssets(GetAssetsParams{Ids: []string{"bitcoin", "ethereum"}})
+	require.NoError(t, err)
+	require.Len(t, assets.Data, 5)
+	asset, err := api.GetAsset("polkadot")
+	require.NoError(t, err)
+	require.Equal(t, "DOT", asset.Asset.Symbol)
+	history, err := api.GetAssetHistory(GetAssetHistoryParams{Id: "polkadot", HistoryParams: HistoryParams{Interval: M30}})
+	require.NoError(t, err)
+	require.Len(t, history.Data, 5)
+	markets, err := api.GetAssetMarkets(GetAssetMarketsParams{Id: "polkadot"})
+	require.NoError(t, err)
+	require.Len(t, markets.Data, 7)
+	rates, err := api.GetRates()
+	require.NoError(t, err)
+	require.Len(t, rates.Data, 5)
+	rate, err := api.GetRate("usd-coin")
+	require.NoError(t, err)
+	require.Equal(t, "USDC", rate.Data.Symbol)
+	exchanges, err := api.GetExchanges()
+	require.NoError(t, err)
+	require.Equal(t, "binance", exchanges.Data[0].ExchangeId)
+	exchange, err := api.GetExchange("kraken")
+	require.NoError(t, err)
+	require.Equal(t, 141, exchange.Data.TradingPairs)
+	allMarkets, err := api.GetMarkets(GetMarketsParams{ExchangeId: "binance"})
+	require.NoError(t, err)
+	require.Len(t, allMarkets.Data, 7)
+	candles, err := api.GetCandles(GetCandlesParams{Exchange: "binance", BaseId: "polkadot", QuoteId: "tether", HistoryParams: HistoryParams{Interval: M5}})
+	require.NoError(t, err)
+	require.Len(t, candles.Data, 10)
+
+	_, err = api.GetAsset("dogecoin")
+	require.True(t, errors.Is(err, NoInteractionError))
+}
+
+func TestRecordingTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(`{"data":{"id":"usd-coin","symbol":"USDC","currencySymbol":null,"type":"crypto","rateUsd":"1"},"timestamp":1}`))
+		_ = gz.Close()
+	}))
+	defer srv.Close()
+
+	cassette := NewCassette()
+	client := NewClient(WithHttpClient(&http.Client{Transport: NewRecordingTransport(cassette, nil)}))
+	var live RateData
+	require.NoError(t, client.Do(srv.URL+"/rates/usd-coin", nil, &live))
+	require.Equal(t, "USDC", live.Data.Symbol)
+
+	var buf bytes.Buffer
+	require.NoError(t, cassette.Save(&buf))
+	loaded, err := LoadCassette(&buf)
+	require.NoError(t, err)
+	interactions := loaded.Interactions()
+	require.Len(t, interactions, 1)
+	require.Equal(t, http.StatusOK, interactions[0].Status)
+	require.Empty(t, interactions[0].Header.Get("Content-Encoding"))
+
+	srv.Close()
+	client = NewClient(WithHttpClient(&http.Client{Transport: NewReplayingTransport(loaded)}))
+	var replayed RateData
+	require.NoError(t, client.Do(srv.URL+"/rates/usd-coin", nil, &replayed))
+	require.Equal(t, live, replayed)
+}
+
+func TestRecordingTransport_Deflate(t *testing.T) {
+	body := `{"data":{"id":"usd-coin","symbol":"USDC","currencySymbol":null,"type":"crypto","rateUsd":"1"},"timestamp":1}`
+	for name, writer := range map[string]func(io.Writer) io.WriteCloser{
+		"zlib": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"raw": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+	} {
+		writer := writer
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "deflate")
+				zw := writer(w)
+				_, _ = zw.Write([]byte(body))
+				_ = zw.Close()
+			}))
+			defer srv.Close()
+
+			cassette := NewCassette()
+			client := NewClient(WithDeflateCompression(), WithHttpClient(&http.Client{Transport: NewRecordingTransport(cassette, nil)}))
+			var live RateData
+			require.NoError(t, client.Do(srv.URL+"/rates/usd-coin", nil, &live))
+			require.Equal(t, "USDC", live.Data.Symbol)
+			interactions := cassette.Interactions()
+			require.Len(t, interactions, 1)
+			require.JSONEq(t, body, string(interactions[0].Body))
+
+			client = NewClient(WithDeflateCompression(), WithHttpClient(&http.Client{Transport: http.DefaultTransport}))
+			var direct RateData
+			require.NoError(t, client.Do(srv.URL+"/rates/usd-coin", nil, &direct))
+			require.Equal(t, live, direct)
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package coincap
 
 import (
+	"bufio"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,8 +14,6 @@ import (
 
 const url = "https://api.coincap.io/v2"
 
-//const wsUrl = "wss://ws.coincap.io/"
-
 type CompressionType string
 
 const (
@@ -19,14 +21,46 @@ const (
 	Deflate CompressionType = "deflate"
 )
 
+var NotFoundError = errors.New("not found")
+var RateLimitError = errors.New("rate limit exceeded")
+
+// ApiError is returned for non 2xx responses, with the "error" message of the body.
+type ApiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("coincap: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *ApiError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return target == NotFoundError
+	case http.StatusTooManyRequests:
+		return target == RateLimitError
+	default:
+		return false
+	}
+}
+
 type Client struct {
+	baseUrl     string
 	httpClient  *http.Client
 	bearerToken string
 	compression CompressionType
+
+	schemaMode     SchemaMode
+	schemaCallback SchemaCallback
+
+	validationMode     ValidationMode
+	validationCallback ValidationCallback
 }
 
 func NewClient(options ...Option) *Client {
 	client := &Client{
+		baseUrl:     url,
 		httpClient:  http.DefaultClient,
 		compression: Gzip,
 	}
@@ -38,6 +72,7 @@ func NewClient(options ...Option) *Client {
 
 type Option func(*Client)
 
+func WithBaseUrl(u string) Option           { return func(c *Client) { c.baseUrl = u } }
 func WithHttpClient(hc *http.Client) Option { return func(c *Client) { c.httpClient = hc } }
 func WithBearerToken(bt string) Option      { return func(c *Client) { c.bearerToken = bt } }
 func WithGzipCompression() Option           { return func(c *Client) { c.compression = Gzip } }
@@ -65,20 +100,43 @@ func (c *Client) Do(url string, params queryParams, ptr interface{}) error {
 	if err != nil {
 		return err
 	}
-	var reader io.ReadCloser
-	switch enc := res.Header.Get("Content-Encoding"); enc {
-	case "deflate":
-		fallthrough
-	case "gzip":
-		reader, err = gzip.NewReader(res.Body)
-		if err != nil {
+	defer res.Body.Close()
+	reader, err := decodeBody(res)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(reader).Decode(&body)
+		return &ApiError{StatusCode: res.StatusCode, Message: body.Error}
+	}
+	if c.schemaMode == SchemaIgnore {
+		if err := json.NewDecoder(reader).Decode(ptr); err != nil {
 			return err
 		}
-		defer reader.Close()
-	default:
-		reader = res.Body
+		return c.afterDecode(url, ptr)
+	}
+	bs, err := io.ReadAll(reader)
+	if err != nil {
+		return err
 	}
-	return json.NewDecoder(reader).Decode(ptr)
+	if err := json.Unmarshal(bs, ptr); err != nil {
+		return err
+	}
+	if err := c.checkSchema(url, bs, ptr); err != nil {
+		return err
+	}
+	return c.afterDecode(url, ptr)
+}
+
+func (c *Client) afterDecode(url string, ptr interface{}) error {
+	if c.validationMode != ValidationIgnore {
+		return c.validate(url, ptr)
+	}
+	return nil
 }
 
 type Api interface {
@@ -100,4 +158,22 @@ type Api interface {
 
 func assertApiInterface() {
 	var _ Api = (*Client)(nil)
+	var _ Api = (*FakeApi)(nil)
+}
+
+// decodeBody undoes the Content-Encoding of res. Deflate bodies are zlib
+// wrapped as the spec says, but raw deflate streams are accepted as well.
+func decodeBody(res *http.Response) (io.ReadCloser, error) {
+	switch res.Header.Get("Content-Encoding") {
+	case "gzip":
+		return gzip.NewReader(res.Body)
+	case "deflate":
+		br := bufio.NewReader(res.Body)
+		if h, err := br.Peek(2); err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	default:
+		return io.NopCloser(res.Body), nil
+	}
 }
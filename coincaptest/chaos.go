@@ -0,0 +1,225 @@
+package coincaptest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Fault int
+
+const (
+	NoFault Fault = iota
+	TimeoutFault
+	RateLimitFault
+	ServerErrorFault
+	TruncatedBodyFault
+	CorruptGzipFault
+	MalformedJsonFault
+)
+
+func (f Fault) String() string {
+	switch f {
+	case NoFault:
+		return "none"
+	case TimeoutFault:
+		return "timeout"
+	case RateLimitFault:
+		return "rate limit"
+	case ServerErrorFault:
+		return "server error"
+	case TruncatedBodyFault:
+		return "truncated body"
+	case CorruptGzipFault:
+		return "corrupt gzip"
+	case MalformedJsonFault:
+		return "malformed json"
+	default:
+		return ""
+	}
+}
+
+// ChaosConfig rates are probabilities in [0, 1], at most one fault is
+// injected per request. Their sum should not exceed 1.
+type ChaosConfig struct {
+	Seed          int64         // same seed, same sequence of faults
+	Latency       time.Duration // added to every request
+	LatencyJitter time.Duration // optional, uniform extra latency in [0, LatencyJitter)
+	TimeoutAfter  time.Duration // optional, wait before a TimeoutFault returns
+
+	TimeoutRate     float64
+	RateLimitRate   float64 // 429 Too Many Requests
+	ServerErrorRate float64 // 500, 502 or 503
+	TruncateRate    float64 // body ends early with io.ErrUnexpectedEOF
+	CorruptGzipRate float64 // gzip encoded body with flipped bytes
+	MalformedRate   float64 // body that isn't valid JSON
+}
+
+// ChaosTransport wraps a RoundTripper and injects faults, e.g.
+// coincap.WithHttpClient(&http.Client{Transport: NewChaosTransport(nil, cfg)}).
+type ChaosTransport struct {
+	next   http.RoundTripper
+	config ChaosConfig
+	mu     sync.Mutex
+	rand   *rand.Rand
+	stats  map[Fault]int
+}
+
+func NewChaosTransport(next http.RoundTripper, config ChaosConfig) *ChaosTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &ChaosTransport{next: next, config: config, rand: rand.New(rand.NewSource(config.Seed)), stats: map[Fault]int{}}
+}
+
+// Stats counts the requests per injected fault, NoFault included.
+func (t *ChaosTransport) Stats() map[Fault]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make(map[Fault]int, len(t.stats))
+	for k, v := range t.stats {
+		stats[k] = v
+	}
+	return stats
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "chaos: request timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func (t *ChaosTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	fault, latency, status := t.draw()
+	if err := sleep(r, latency); err != nil {
+		return nil, err
+	}
+	switch fault {
+	case TimeoutFault:
+		if err := sleep(r, t.config.TimeoutAfter); err != nil {
+			return nil, err
+		}
+		return nil, timeoutError{}
+	case RateLimitFault, ServerErrorFault:
+		body := []byte(fmt.Sprintf(`{"error":"%s","timestamp":%d}`, http.StatusText(status), time.Now().UnixMilli()))
+		return response(r, status, http.Header{"Content-Type": {"application/json"}}, io.NopCloser(bytes.NewReader(body)), len(body)), nil
+	}
+	res, err := t.next.RoundTrip(r)
+	if err != nil || fault == NoFault {
+		return res, err
+	}
+	body, err := decodedBody(res)
+	if err != nil {
+		return nil, err
+	}
+	header := res.Header.Clone()
+	header.Del("Content-Encoding")
+	switch fault {
+	case TruncatedBodyFault:
+		half := bytes.NewReader(body[:len(body)/2])
+		return response(r, res.StatusCode, header, io.NopCloser(io.MultiReader(half, errReader{io.ErrUnexpectedEOF})), len(body)), nil
+	case CorruptGzipFault:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write(body)
+		_ = gz.Close()
+		bs := buf.Bytes()
+		for i := len(bs) / 2; i < len(bs)/2+4; i++ { // decoders may stop before the trailer checksum
+			bs[i] ^= 0xff
+		}
+		header.Set("Content-Encoding", "gzip")
+		return response(r, res.StatusCode, header, io.NopCloser(bytes.NewReader(bs)), len(bs)), nil
+	default: // MalformedJsonFault
+		bs := append([]byte("{\"data\":"), body[:len(body)/2]...)
+		bs = append(bs, []byte("]]")...)
+		return response(r, res.StatusCode, header, io.NopCloser(bytes.NewReader(bs)), len(bs)), nil
+	}
+}
+
+// draw picks the fault, latency and error status of a request.
+func (t *ChaosTransport) draw() (Fault, time.Duration, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	latency := t.config.Latency
+	if t.config.LatencyJitter > 0 {
+		latency += time.Duration(t.rand.Int63n(int64(t.config.LatencyJitter)))
+	}
+	fault := NoFault
+	p := t.rand.Float64()
+	for _, c := range []struct {
+		fault Fault
+		rate  float64
+	}{
+		{TimeoutFault, t.config.TimeoutRate},
+		{RateLimitFault, t.config.RateLimitRate},
+		{ServerErrorFault, t.config.ServerErrorRate},
+		{TruncatedBodyFault, t.config.TruncateRate},
+		{CorruptGzipFault, t.config.CorruptGzipRate},
+		{MalformedJsonFault, t.config.MalformedRate},
+	} {
+		if p < c.rate {
+			fault = c.fault
+			break
+		}
+		p -= c.rate
+	}
+	status := http.StatusTooManyRequests
+	if fault == ServerErrorFault {
+		status = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}[t.rand.Intn(3)]
+	}
+	t.stats[fault]++
+	return fault, latency, status
+}
+
+func sleep(r *http.Request, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-r.Context().Done():
+		return r.Context().Err()
+	}
+}
+
+func decodedBody(res *http.Response) ([]byte, error) {
+	defer res.Body.Close()
+	var reader io.Reader = res.Body
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	return io.ReadAll(reader)
+}
+
+func response(r *http.Request, status int, header http.Header, body io.ReadCloser, length int) *http.Response {
+	header.Set("Content-Length", strconv.Itoa(length))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          body,
+		ContentLength: int64(length),
+		Request:       r,
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,85 @@
+package coincaptest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+func chaosClient(s *Server, config ChaosConfig) (*coincap.Client, *ChaosTransport) {
+	transport := NewChaosTransport(nil, config)
+	return s.Client(coincap.WithHttpClient(&http.Client{Transport: transport})), transport
+}
+
+func TestChaosTransport_Faults(t *testing.T) {
+	s := fixtureServer(t)
+
+	client, _ := chaosClient(s, ChaosConfig{})
+	_, err := client.GetRates()
+	require.NoError(t, err)
+
+	client, _ = chaosClient(s, ChaosConfig{TimeoutRate: 1})
+	_, err = client.GetRates()
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr))
+	require.True(t, netErr.Timeout())
+
+	client, _ = chaosClient(s, ChaosConfig{RateLimitRate: 1})
+	_, err = client.GetRates()
+	require.True(t, errors.Is(err, coincap.RateLimitError))
+
+	client, _ = chaosClient(s, ChaosConfig{ServerErrorRate: 1})
+	_, err = client.GetRates()
+	var apiErr *coincap.ApiError
+	require.True(t, errors.As(err, &apiErr))
+	require.True(t, apiErr.StatusCode >= 500)
+
+	client, _ = chaosClient(s, ChaosConfig{TruncateRate: 1})
+	_, err = client.GetRates()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	client, _ = chaosClient(s, ChaosConfig{CorruptGzipRate: 1})
+	_, err = client.GetRates()
+	require.Error(t, err)
+
+	client, _ = chaosClient(s, ChaosConfig{MalformedRate: 1})
+	_, err = client.GetRates()
+	require.Error(t, err)
+}
+
+func TestChaosTransport_Seed(t *testing.T) {
+	s := fixtureServer(t)
+	config := ChaosConfig{Seed: 42, RateLimitRate: 0.3, ServerErrorRate: 0.2, MalformedRate: 0.1}
+	run := func() []bool {
+		client, _ := chaosClient(s, config)
+		failed := make([]bool, 50)
+		for i := range failed {
+			_, err := client.GetRates()
+			failed[i] = err != nil
+		}
+		return failed
+	}
+	require.Equal(t, run(), run())
+
+	client, transport := chaosClient(s, config)
+	for i := 0; i < 1000; i++ {
+		_, _ = client.GetRates()
+	}
+	stats := transport.Stats()
+	require.InDelta(t, 300, stats[RateLimitFault], 60)
+	require.InDelta(t, 400, stats[NoFault], 60)
+}
+
+func TestChaosTransport_Latency(t *testing.T) {
+	client, _ := chaosClient(fixtureServer(t), ChaosConfig{Latency: time.Millisecond * 50})
+	start := time.Now()
+	_, err := client.GetRates()
+	require.NoError(t, err)
+	require.True(t, time.Since(start) >= time.Millisecond*50)
+}
@@ -0,0 +1,360 @@
+// Package coincaptest serves the CoinCap v2 REST API from in-memory data for
+// integration tests against coincap.Client.
+package coincaptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/esenmx/coincap-go"
+)
+
+type CandleKey struct {
+	Exchange string
+	BaseId   string
+	QuoteId  string
+	Interval coincap.Interval
+}
+
+type Data struct {
+	Assets    []coincap.Asset
+	History   map[string][]coincap.AssetHistory // by asset id
+	Rates     []coincap.Rate
+	Exchanges []coincap.Exchange
+	Markets   []coincap.Market
+	Candles   map[CandleKey][]coincap.Candle
+}
+
+// Fixtures loads the mock/*.json files of the repository. Assets include
+// asset_id (polkadot) and exchanges include exchange (kraken), history
+// belongs to polkadot and candles to binance polkadot/tether at m30.
+func Fixtures(dir string) (Data, error) {
+	var assets coincap.AssetsData
+	var asset coincap.AssetData
+	var history coincap.AssetHistoriesData
+	var rates coincap.RatesData
+	var exchanges coincap.ExchangesData
+	var exchange coincap.ExchangeData
+	var markets coincap.MarketsData
+	var candles coincap.CandlesData
+	for name, ptr := range map[string]interface{}{
+		"assets":        &assets,
+		"asset_id":      &asset,
+		"asset_history": &history,
+		"rates":         &rates,
+		"exchanges":     &exchanges,
+		"exchange":      &exchange,
+		"markets":       &markets,
+		"candles":       &candles,
+	} {
+		bs, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if err != nil {
+			return Data{}, err
+		}
+		if err := json.Unmarshal(bs, ptr); err != nil {
+			return Data{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return Data{
+		Assets:    append(assets.Data, asset.Asset),
+		History:   map[string][]coincap.AssetHistory{"polkadot": history.Data},
+		Rates:     rates.Data,
+		Exchanges: append(exchanges.Data, exchange.Data),
+		Markets:   markets.Data,
+		Candles:   map[CandleKey][]coincap.Candle{{"binance", "polkadot", "tether", coincap.M30}: candles.Data},
+	}, nil
+}
+
+type Server struct {
+	*httptest.Server
+	mu       sync.Mutex
+	data     Data
+	failures []int
+	limit    int
+	window   time.Duration
+	requests []time.Time
+	Now      func() time.Time // response timestamps and rate limiting, time.Now by default
+}
+
+func NewServer(data Data) *Server {
+	s := &Server{data: data, Now: time.Now}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", s.route)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Client returns a coincap.Client pointed at the server.
+func (s *Server) Client(options ...coincap.Option) *coincap.Client {
+	return coincap.NewClient(append([]coincap.Option{coincap.WithBaseUrl(s.URL + "/v2")}, options...)...)
+}
+
+// Update modifies the served data under the server lock.
+func (s *Server) Update(fn func(*Data)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.data)
+}
+
+// FailNext answers the next requests with the given status codes, one each.
+func (s *Server) FailNext(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// SetRateLimit answers 429 once more than n requests arrive within window, zero disables it.
+func (s *Server) SetRateLimit(n int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit, s.window, s.requests = n, window, nil
+}
+
+type apiError struct {
+	status  int
+	message string
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	if err := s.admit(now); err != nil {
+		s.writeError(w, now, err)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v2"), "/"), "/")
+	q := r.URL.Query()
+	var data interface{}
+	var err *apiError
+	switch {
+	case len(parts) == 1 && parts[0] == "assets":
+		data, err = s.assets(q)
+	case len(parts) == 2 && parts[0] == "assets":
+		data, err = s.asset(parts[1])
+	case len(parts) == 3 && parts[0] == "assets" && parts[2] == "history":
+		data, err = s.history(parts[1], q)
+	case len(parts) == 3 && parts[0] == "assets" && parts[2] == "markets":
+		data, err = s.assetMarkets(parts[1], q)
+	case len(parts) == 1 && parts[0] == "rates":
+		data = s.data.Rates
+	case len(parts) == 2 && parts[0] == "rates":
+		data, err = find(s.data.Rates, parts[1], func(r coincap.Rate) string { return r.Id })
+	case len(parts) == 1 && parts[0] == "exchanges":
+		data = s.data.Exchanges
+	case len(parts) == 2 && parts[0] == "exchanges":
+		data, err = find(s.data.Exchanges, parts[1], func(e coincap.Exchange) string { return e.ExchangeId })
+	case len(parts) == 1 && parts[0] == "markets":
+		data, err = s.markets(q)
+	case len(parts) == 1 && parts[0] == "candles":
+		data, err = s.candles(q)
+	default:
+		err = &apiError{http.StatusNotFound, "Not found"}
+	}
+	if err != nil {
+		s.writeError(w, now, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "timestamp": now.UnixMilli()})
+}
+
+func (s *Server) admit(now time.Time) *apiError {
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		return &apiError{status, http.StatusText(status)}
+	}
+	if s.limit <= 0 {
+		return nil
+	}
+	recent := s.requests[:0]
+	for _, t := range s.requests {
+		if now.Sub(t) < s.window {
+			recent = append(recent, t)
+		}
+	}
+	s.requests = recent
+	if len(s.requests) >= s.limit {
+		return &apiError{http.StatusTooManyRequests, "Too many requests, please try again later."}
+	}
+	s.requests = append(s.requests, now)
+	return nil
+}
+
+func (s *Server) writeError(w http.ResponseWriter, now time.Time, err *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": err.message, "timestamp": now.UnixMilli()})
+}
+
+func (s *Server) assets(q map[string][]string) (interface{}, *apiError) {
+	assets := append([]coincap.Asset(nil), s.data.Assets...)
+	sort.SliceStable(assets, func(i, j int) bool { return assets[i].Rank < assets[j].Rank })
+	if ids := get(q, "ids"); len(ids) > 0 {
+		set := map[string]bool{}
+		for _, id := range strings.Split(ids, ",") {
+			set[id] = true
+		}
+		assets = filter(assets, func(a coincap.Asset) bool { return set[a.Id] })
+	}
+	if search := strings.ToLower(get(q, "search")); len(search) > 0 {
+		assets = filter(assets, func(a coincap.Asset) bool {
+			return strings.Contains(strings.ToLower(a.Id), search) || strings.Contains(strings.ToLower(a.Symbol), search)
+		})
+	}
+	return paginate(assets, q)
+}
+
+func (s *Server) asset(id string) (interface{}, *apiError) {
+	return find(s.data.Assets, id, func(a coincap.Asset) string { return a.Id })
+}
+
+func (s *Server) history(id string, q map[string][]string) (interface{}, *apiError) {
+	if _, err := s.asset(id); err != nil {
+		return nil, err
+	}
+	interval, start, end, err := historyParams(q)
+	if err != nil {
+		return nil, err
+	}
+	ms := interval.Value().Milliseconds()
+	return filter(s.data.History[id], func(h coincap.AssetHistory) bool {
+		return h.Time%ms == 0 && inRange(h.Time, start, end)
+	}), nil
+}
+
+func (s *Server) assetMarkets(id string, q map[string][]string) (interface{}, *apiError) {
+	if _, err := s.asset(id); err != nil {
+		return nil, err
+	}
+	markets := filter(s.data.Markets, func(m coincap.Market) bool { return m.BaseId == id })
+	var total float64
+	for _, m := range markets {
+		total += m.VolumeUsd24Hr
+	}
+	sort.SliceStable(markets, func(i, j int) bool { return markets[i].VolumeUsd24Hr > markets[j].VolumeUsd24Hr })
+	assetMarkets := make([]coincap.AssetMarket, len(markets))
+	for i, m := range markets {
+		assetMarkets[i] = coincap.AssetMarket{
+			ExchangeId:    m.ExchangeId,
+			BaseId:        m.BaseId,
+			QuoteId:       m.QuoteId,
+			BaseSymbol:    m.BaseSymbol,
+			QuoteSymbol:   m.QuoteSymbol,
+			VolumeUsd24Hr: m.VolumeUsd24Hr,
+			PriceUsd:      m.PriceUsd,
+		}
+		if total > 0 {
+			assetMarkets[i].VolumePercent = m.VolumeUsd24Hr / total * 100
+		}
+	}
+	return paginate(assetMarkets, q)
+}
+
+func (s *Server) markets(q map[string][]string) (interface{}, *apiError) {
+	eq := func(param, v string) bool {
+		p := get(q, param)
+		return len(p) == 0 || strings.EqualFold(p, v)
+	}
+	markets := filter(s.data.Markets, func(m coincap.Market) bool {
+		return eq("exchangeId", m.ExchangeId) &&
+			eq("baseSymbol", m.BaseSymbol) && eq("quoteSymbol", m.QuoteSymbol) &&
+			eq("baseId", m.BaseId) && eq("quoteId", m.QuoteId) &&
+			(eq("assetSymbol", m.BaseSymbol) || eq("assetSymbol", m.QuoteSymbol)) &&
+			(eq("assetId", m.BaseId) || eq("assetId", m.QuoteId))
+	})
+	return paginate(markets, q)
+}
+
+func (s *Server) candles(q map[string][]string) (interface{}, *apiError) {
+	exchange, base, quote := get(q, "exchange"), get(q, "baseId"), get(q, "quoteId")
+	if len(exchange) == 0 || len(base) == 0 || len(quote) == 0 {
+		return nil, &apiError{http.StatusBadRequest, "missing exchange, baseId or quoteId"}
+	}
+	interval, start, end, err := historyParams(q)
+	if err != nil {
+		return nil, err
+	}
+	candles := s.data.Candles[CandleKey{exchange, base, quote, interval}]
+	return filter(candles, func(c coincap.Candle) bool { return inRange(c.Period, start, end) }), nil
+}
+
+func historyParams(q map[string][]string) (coincap.Interval, int64, int64, *apiError) {
+	interval, err := coincap.ParseInterval(get(q, "interval"))
+	if err != nil {
+		return 0, 0, 0, &apiError{http.StatusBadRequest, "missing interval"}
+	}
+	start, startErr := strconv.ParseInt(get(q, "start"), 10, 64)
+	end, endErr := strconv.ParseInt(get(q, "end"), 10, 64)
+	hasStart, hasEnd := len(get(q, "start")) > 0, len(get(q, "end")) > 0
+	if hasStart != hasEnd || (hasStart && (startErr != nil || endErr != nil || start >= end)) {
+		return 0, 0, 0, &apiError{http.StatusBadRequest, "invalid start or end"}
+	}
+	return interval, start, end, nil
+}
+
+func inRange(t, start, end int64) bool {
+	return end == 0 || (t >= start && t < end)
+}
+
+func paginate[T any](rows []T, q map[string][]string) ([]T, *apiError) {
+	limit, offset := 100, 0
+	if v := get(q, "limit"); len(v) > 0 {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > coincap.MaxLimit {
+			return nil, &apiError{http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", coincap.MaxLimit)}
+		}
+		limit = n
+	}
+	if v := get(q, "offset"); len(v) > 0 {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, &apiError{http.StatusBadRequest, "invalid offset"}
+		}
+		offset = n
+	}
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+	if offset+limit < len(rows) {
+		rows = rows[:offset+limit]
+	}
+	return rows[offset:], nil
+}
+
+func find[T any](rows []T, id string, key func(T) string) (T, *apiError) {
+	for _, row := range rows {
+		if key(row) == id {
+			return row, nil
+		}
+	}
+	var zero T
+	return zero, &apiError{http.StatusNotFound, fmt.Sprintf("%s not found", id)}
+}
+
+func filter[T any](rows []T, keep func(T) bool) []T {
+	kept := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if keep(row) {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+func get(q map[string][]string, key string) string {
+	if v := q[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package coincaptest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureServer(t *testing.T) *Server {
+	data, err := Fixtures("../mock")
+	require.NoError(t, err)
+	s := NewServer(data)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestServer_Assets(t *testing.T) {
+	client := fixtureServer(t).Client()
+	assets, err := client.GetAssets(coincap.GetAssetsParams{LimitOffsetParams: coincap.LimitOffsetParams{Limit: 2, Offset: 1}})
+	require.NoError(t, err)
+	require.Len(t, assets.Data, 2)
+	require.Equal(t, 2, assets.Data[0].Rank)
+
+	assets, err = client.GetAssets(coincap.GetAssetsParams{Search: "btc"})
+	require.NoError(t, err)
+	require.Len(t, assets.Data, 1)
+	require.Equal(t, "bitcoin", assets.Data[0].Id)
+
+	assets, err = client.GetAssets(coincap.GetAssetsParams{Ids: []string{"bitcoin", "ethereum", "nope"}})
+	require.NoError(t, err)
+	require.Len(t, assets.Data, 2)
+
+	asset, err := client.GetAsset("bitcoin")
+	require.NoError(t, err)
+	require.Equal(t, "BTC", asset.Asset.Symbol)
+
+	_, err = client.GetAsset("bitcoin1")
+	require.True(t, errors.Is(err, coincap.NotFoundError))
+	var apiErr *coincap.ApiError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, "bitcoin1 not found", apiErr.Message)
+}
+
+func TestServer_History(t *testing.T) {
+	client := fixtureServer(t).Client()
+	start := time.Date(2021, 7, 26, 11, 0, 0, 0, time.UTC)
+	history, err := client.GetAssetHistory(coincap.GetAssetHistoryParams{
+		Id:            "polkadot",
+		HistoryParams: coincap.HistoryParams{Interval: coincap.M30, Start: start, End: start.Add(time.Hour)},
+	})
+	require.NoError(t, err)
+	require.Len(t, history.Data, 2)
+	require.Equal(t, start, history.Data[0].Date)
+
+	history, err = client.GetAssetHistory(coincap.GetAssetHistoryParams{Id: "polkadot", HistoryParams: coincap.HistoryParams{Interval: coincap.H1}})
+	require.NoError(t, err)
+	require.Len(t, history.Data, 3)
+
+	candles, err := client.GetCandles(coincap.GetCandlesParams{Exchange: "binance", BaseId: "polkadot", QuoteId: "tether", HistoryParams: coincap.HistoryParams{Interval: coincap.M30}})
+	require.NoError(t, err)
+	require.Len(t, candles.Data, 10)
+	candles, err = client.GetCandles(coincap.GetCandlesParams{Exchange: "binance", BaseId: "polkadot", QuoteId: "tether", HistoryParams: coincap.HistoryParams{Interval: coincap.H1}})
+	require.NoError(t, err)
+	require.Empty(t, candles.Data)
+}
+
+func TestServer_Markets(t *testing.T) {
+	s := fixtureServer(t)
+	s.Update(func(d *Data) { d.Assets = append(d.Assets, coincap.Asset{Id: "solana", Symbol: "SOL", Rank: 7}) })
+	client := s.Client()
+	markets, err := client.GetMarkets(coincap.GetMarketsParams{ExchangeId: "binance"})
+	require.NoError(t, err)
+	require.NotEmpty(t, markets.Data)
+	for _, m := range markets.Data {
+		require.Equal(t, "binance", m.ExchangeId)
+	}
+	markets, err = client.GetMarkets(coincap.GetMarketsParams{AssetSymbol: "usdt", LimitOffsetParams: coincap.LimitOffsetParams{Limit: 2}})
+	require.NoError(t, err)
+	require.Len(t, markets.Data, 2)
+
+	assetMarkets, err := client.GetAssetMarkets(coincap.GetAssetMarketsParams{Id: "solana"})
+	require.NoError(t, err)
+	require.NotEmpty(t, assetMarkets.Data)
+	var total float64
+	for _, m := range assetMarkets.Data {
+		require.Equal(t, "solana", m.BaseId)
+		total += m.VolumePercent
+	}
+	require.InDelta(t, 100, total, 1e-9)
+}
+
+func TestServer_RatesExchanges(t *testing.T) {
+	client := fixtureServer(t).Client()
+	rates, err := client.GetRates()
+	require.NoError(t, err)
+	require.Len(t, rates.Data, 5)
+	rate, err := client.GetRate("turkish-lira")
+	require.NoError(t, err)
+	require.Equal(t, "TRY", rate.Data.Symbol)
+	exchanges, err := client.GetExchanges()
+	require.NoError(t, err)
+	require.Len(t, exchanges.Data, 6)
+	exchange, err := client.GetExchange("binance")
+	require.NoError(t, err)
+	require.True(t, exchange.Data.Socket)
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := fixtureServer(t)
+	client := s.Client()
+	s.FailNext(http.StatusInternalServerError)
+	_, err := client.GetRates()
+	var apiErr *coincap.ApiError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+
+	now := time.Date(2021, 7, 26, 0, 0, 0, 0, time.UTC)
+	s.Now = func() time.Time { return now }
+	s.SetRateLimit(2, time.Minute)
+	_, err = client.GetRates()
+	require.NoError(t, err)
+	_, err = client.GetRates()
+	require.NoError(t, err)
+	_, err = client.GetRates()
+	require.True(t, errors.Is(err, coincap.RateLimitError))
+	now = now.Add(time.Minute)
+	_, err = client.GetRates()
+	require.NoError(t, err)
+}
@@ -9,15 +9,15 @@ import "time"
 type Interval int
 
 const (
-	M1  Interval = iota // max range: 1day
-	M5                  // max range: 5day
-	M15                 // max range: 7day
-	M30                 // max range: 14day
-	H1                  // max range: 30day
-	H2                  // max range: 61day
-	H6                  // max range: 183day
-	H12                 // max range: 365day
-	D1                  // max range: 7305day
+	M1  Interval = iota + 1 // max range: 1day
+	M5                      // max range: 5day
+	M15                     // max range: 7day
+	M30                     // max range: 14day
+	H1                      // max range: 30day
+	H2                      // max range: 61day
+	H6                      // max range: 183day
+	H12                     // max range: 365day
+	D1                      // max range: 7305day
 )
 
 func (i Interval) String() string {
@@ -70,3 +70,12 @@ func (i Interval) Value() time.Duration {
 	}
 
 }
+
+func ParseInterval(s string) (Interval, error) {
+	for i := M1; i <= D1; i++ {
+		if i.String() == s {
+			return i, nil
+		}
+	}
+	return 0, InvalidParameterError
+}
@@ -0,0 +1,188 @@
+package coincap
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Call is a recorded FakeApi invocation, Arg is the params struct or the id.
+type Call struct {
+	Method string
+	Arg    interface{}
+}
+
+// TestingT is the subset of *testing.T used by the FakeApi assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// FakeApi is a programmable Api for unit tests. Methods without a function
+// set return zero data. Every call is recorded, including failed ones.
+type FakeApi struct {
+	GetAssetsFunc       func(GetAssetsParams) (AssetsData, error)
+	GetAssetFunc        func(id string) (AssetData, error)
+	GetAssetHistoryFunc func(GetAssetHistoryParams) (AssetHistoriesData, error)
+	GetAssetMarketsFunc func(GetAssetMarketsParams) (AssetMarketsData, error)
+	GetRatesFunc        func() (RatesData, error)
+	GetRateFunc         func(id string) (RateData, error)
+	GetExchangesFunc    func() (ExchangesData, error)
+	GetExchangeFunc     func(id string) (ExchangeData, error)
+	GetMarketsFunc      func(GetMarketsParams) (MarketsData, error)
+	GetCandlesFunc      func(GetCandlesParams) (CandlesData, error)
+
+	mu    sync.Mutex
+	calls []Call
+	fails map[string][]error
+}
+
+// FailNext makes the next calls of method, e.g. "GetAssets", fail with errs, one each.
+func (f *FakeApi) FailNext(method string, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fails == nil {
+		f.fails = map[string][]error{}
+	}
+	f.fails[method] = append(f.fails[method], errs...)
+}
+
+func (f *FakeApi) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+func (f *FakeApi) CallsTo(method string) []Call {
+	var calls []Call
+	for _, c := range f.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+func (f *FakeApi) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls, f.fails = nil, nil
+}
+
+// AssertCalled checks method was called, with an equal arg when one is given.
+func (f *FakeApi) AssertCalled(t TestingT, method string, arg ...interface{}) bool {
+	t.Helper()
+	for _, c := range f.CallsTo(method) {
+		if len(arg) == 0 || reflect.DeepEqual(c.Arg, arg[0]) {
+			return true
+		}
+	}
+	if len(arg) == 0 {
+		t.Errorf("expected a call to %s, got calls %v", method, f.Calls())
+	} else {
+		t.Errorf("expected a call to %s(%+v), got calls %v", method, arg[0], f.Calls())
+	}
+	return false
+}
+
+func (f *FakeApi) AssertNotCalled(t TestingT, method string) bool {
+	t.Helper()
+	if calls := f.CallsTo(method); len(calls) > 0 {
+		t.Errorf("expected no call to %s, got %v", method, calls)
+		return false
+	}
+	return true
+}
+
+func (f *FakeApi) AssertCallCount(t TestingT, method string, n int) bool {
+	t.Helper()
+	if calls := f.CallsTo(method); len(calls) != n {
+		t.Errorf("expected %d calls to %s, got %d", n, method, len(calls))
+		return false
+	}
+	return true
+}
+
+func (c Call) String() string { return fmt.Sprintf("%s(%+v)", c.Method, c.Arg) }
+
+// record returns the injected failure of the call, if any.
+func (f *FakeApi) record(method string, arg interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Arg: arg})
+	if errs := f.fails[method]; len(errs) > 0 {
+		f.fails[method] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+func (f *FakeApi) GetAssets(params GetAssetsParams) (AssetsData, error) {
+	if err := f.record("GetAssets", params); err != nil || f.GetAssetsFunc == nil {
+		return AssetsData{}, err
+	}
+	return f.GetAssetsFunc(params)
+}
+
+func (f *FakeApi) GetAsset(id string) (AssetData, error) {
+	if err := f.record("GetAsset", id); err != nil || f.GetAssetFunc == nil {
+		return AssetData{}, err
+	}
+	return f.GetAssetFunc(id)
+}
+
+func (f *FakeApi) GetAssetHistory(params GetAssetHistoryParams) (AssetHistoriesData, error) {
+	if err := f.record("GetAssetHistory", params); err != nil || f.GetAssetHistoryFunc == nil {
+		return AssetHistoriesData{}, err
+	}
+	return f.GetAssetHistoryFunc(params)
+}
+
+func (f *FakeApi) GetAssetMarkets(params GetAssetMarketsParams) (AssetMarketsData, error) {
+	if err := f.record("GetAssetMarkets", params); err != nil || f.GetAssetMarketsFunc == nil {
+		return AssetMarketsData{}, err
+	}
+	return f.GetAssetMarketsFunc(params)
+}
+
+func (f *FakeApi) GetRates() (RatesData, error) {
+	if err := f.record("GetRates", nil); err != nil || f.GetRatesFunc == nil {
+		return RatesData{}, err
+	}
+	return f.GetRatesFunc()
+}
+
+func (f *FakeApi) GetRate(id string) (RateData, error) {
+	if err := f.record("GetRate", id); err != nil || f.GetRateFunc == nil {
+		return RateData{}, err
+	}
+	return f.GetRateFunc(id)
+}
+
+func (f *FakeApi) GetExchanges() (ExchangesData, error) {
+	if err := f.record("GetExchanges", nil); err != nil || f.GetExchangesFunc == nil {
+		return ExchangesData{}, err
+	}
+	return f.GetExchangesFunc()
+}
+
+func (f *FakeApi) GetExchange(id string) (ExchangeData, error) {
+	if err := f.record("GetExchange", id); err != nil || f.GetExchangeFunc == nil {
+		return ExchangeData{}, err
+	}
+	return f.GetExchangeFunc(id)
+}
+
+func (f *FakeApi) GetMarkets(params GetMarketsParams) (MarketsData, error) {
+	if err := f.record("GetMarkets", params); err != nil || f.GetMarketsFunc == nil {
+		return MarketsData{}, err
+	}
+	return f.GetMarketsFunc(params)
+}
+
+func (f *FakeApi) GetCandles(params GetCandlesParams) (CandlesData, error) {
+	if err := f.record("GetCandles", params); err != nil || f.GetCandlesFunc == nil {
+		return CandlesData{}, err
+	}
+	return f.GetCandlesFunc(params)
+}
@@ -0,0 +1,57 @@
+package coincap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFakeApi(t *testing.T) {
+	fake := &FakeApi{
+		GetAssetFunc: func(id string) (AssetData, error) { return AssetData{Asset: Asset{Id: id}}, nil },
+	}
+	var api Api = fake
+	asset, err := api.GetAsset("bitcoin")
+	require.NoError(t, err)
+	require.Equal(t, "bitcoin", asset.Asset.Id)
+
+	rates, err := api.GetRates()
+	require.NoError(t, err)
+	require.Empty(t, rates.Data)
+
+	boom := errors.New("boom")
+	fake.FailNext("GetAsset", boom)
+	_, err = api.GetAsset("ethereum")
+	require.Equal(t, boom, err)
+	_, err = api.GetAsset("ethereum")
+	require.NoError(t, err)
+
+	params := GetMarketsParams{ExchangeId: "binance"}
+	_, _ = api.GetMarkets(params)
+
+	require.Len(t, fake.Calls(), 5)
+	require.Equal(t, []Call{{"GetAsset", "bitcoin"}, {"GetAsset", "ethereum"}, {"GetAsset", "ethereum"}}, fake.CallsTo("GetAsset"))
+	require.True(t, fake.AssertCalled(t, "GetMarkets", params))
+	require.True(t, fake.AssertCallCount(t, "GetAsset", 3))
+	require.True(t, fake.AssertNotCalled(t, "GetCandles"))
+
+	rt := &recordingT{}
+	require.False(t, fake.AssertCalled(rt, "GetMarkets", GetMarketsParams{ExchangeId: "kraken"}))
+	require.False(t, fake.AssertNotCalled(rt, "GetRates"))
+	require.False(t, fake.AssertCallCount(rt, "GetRates", 2))
+	require.Len(t, rt.errors, 3)
+
+	fake.Reset()
+	require.Empty(t, fake.Calls())
+}
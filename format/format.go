@@ -0,0 +1,137 @@
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/esenmx/coincap-go"
+)
+
+// Currency renders USD denominated amounts in the unit of a CoinCap rate.
+type Currency struct {
+	Symbol   string  // optional, prefix symbol e.g. "$", falls back to Code as suffix
+	Code     string  // e.g. "USD", "BTC"
+	RateUsd  float64 // required, USD value of one unit
+	Decimals int     // optional, fixed fraction digits. adaptive when negative
+	Crypto   bool    // crypto units are rendered up to satoshi (8 digit) precision
+}
+
+var USD = Currency{Symbol: "$", Code: "USD", RateUsd: 1, Decimals: -1}
+
+const satoshiDecimals = 8
+
+func FromRate(r coincap.Rate) Currency {
+	c := Currency{Code: r.Symbol, RateUsd: r.RateUsd, Decimals: -1, Crypto: r.Type == "crypto"}
+	if r.CurrencySymbol != nil {
+		c.Symbol = *r.CurrencySymbol
+	}
+	return c
+}
+
+// Price converts amountUsd to the currency and formats it with grouped thousands.
+func (c Currency) Price(amountUsd float64) string {
+	v := c.convert(amountUsd)
+	return c.decorate(v, Number(math.Abs(v), c.decimals(v)))
+}
+
+// Compact formats large amounts with K/M/B/T suffixes, e.g. "$14.96B".
+// Amounts that round up to the next unit use it, 999999 is "$1.00M".
+func (c Currency) Compact(amountUsd float64) string {
+	v := c.convert(amountUsd)
+	abs := math.Abs(v)
+	for _, u := range compactUnits {
+		// the unit below shows 2 decimals too, it must not round up to 1000.00
+		below, _ := strconv.ParseFloat(strconv.FormatFloat(abs/(u.value/1e3), 'f', 2, 64), 64)
+		if abs >= u.value || below >= 1e3 {
+			return c.decorate(v, strconv.FormatFloat(abs/u.value, 'f', 2, 64)+u.suffix)
+		}
+	}
+	return c.Price(amountUsd)
+}
+
+// Percent formats a percentage value such as Asset.ChangePercent24Hr, e.g. "+10.08%".
+// Values rounding to zero render as "0.00%" whatever their sign.
+func Percent(p float64) string {
+	s := strconv.FormatFloat(p, 'f', 2, 64)
+	if strings.Trim(s, "-0.") == "" {
+		return "0.00%"
+	}
+	if p > 0 {
+		s = "+" + s
+	}
+	return s + "%"
+}
+
+// Decimals returns the fraction digits needed to show v with 4 significant digits, at least 2.
+func Decimals(v float64) int {
+	abs := math.Abs(v)
+	if abs >= 1 || abs == 0 {
+		return 2
+	}
+	d := int(-math.Floor(math.Log10(abs))) + 3
+	if d > 12 {
+		return 12
+	}
+	return d
+}
+
+// Number formats v with the given fraction digits and comma separated thousands.
+func Number(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + frac
+}
+
+type compactUnit struct {
+	value  float64
+	suffix string
+}
+
+var compactUnits = []compactUnit{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}}
+
+func (c Currency) convert(amountUsd float64) float64 {
+	if c.RateUsd == 0 {
+		return amountUsd
+	}
+	return amountUsd / c.RateUsd
+}
+
+func (c Currency) decimals(v float64) int {
+	if c.Decimals >= 0 {
+		return c.Decimals
+	}
+	if c.Crypto && math.Abs(v) < 1e3 {
+		s := strings.TrimRight(strconv.FormatFloat(math.Abs(v), 'f', satoshiDecimals, 64), "0")
+		if d := len(s) - strings.IndexByte(s, '.') - 1; d > 2 {
+			return d
+		}
+		return 2
+	}
+	return Decimals(v)
+}
+
+func (c Currency) decorate(v float64, s string) string {
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	if len(c.Symbol) > 0 {
+		return sign + c.Symbol + s
+	}
+	return sign + s + " " + c.Code
+}
@@ -0,0 +1,58 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrency_Price(t *testing.T) {
+	require.Equal(t, "$38,417.05", USD.Price(38417.0478200847774256))
+	require.Equal(t, "$14.77", USD.Price(14.7710918668897673))
+	require.Equal(t, "$0.00001234", USD.Price(0.0000123412))
+	require.Equal(t, "-$0.5000", USD.Price(-0.5))
+	require.Equal(t, "$0.00", USD.Price(0))
+
+	btc := FromRate(coincap.Rate{Symbol: "BTC", Type: "crypto", RateUsd: 40000})
+	require.Equal(t, "0.0005 BTC", btc.Price(20))
+	require.Equal(t, "0.00000005 BTC", btc.Price(0.002))
+	require.Equal(t, "1.00 BTC", btc.Price(40000))
+
+	sym := "Kr"
+	try := FromRate(coincap.Rate{Symbol: "TRY", CurrencySymbol: &sym, Type: "fiat", RateUsd: 0.1})
+	require.Equal(t, "Kr1,000.00", try.Price(100))
+}
+
+func TestCurrency_Compact(t *testing.T) {
+	require.Equal(t, "$14.96B", USD.Compact(14964432257.0276593916630207))
+	require.Equal(t, "$720.97B", USD.Compact(720972966941.8179384548997536))
+	require.Equal(t, "$1.20T", USD.Compact(1.2e12))
+	require.Equal(t, "$567.05M", USD.Compact(567049854.0292255884810806))
+	require.Equal(t, "$999.00", USD.Compact(999))
+
+	for amount, expected := range map[float64]string{
+		999.994:       "$999.99",
+		999.996:       "$1.00K",
+		999994:        "$999.99K",
+		999999:        "$1.00M",
+		-999999:       "-$1.00M",
+		999999999:     "$1.00B",
+		999999999999:  "$1.00T",
+		1e6:           "$1.00M",
+		1e15:          "$1000.00T",
+		999995000:     "$1.00B",
+		999994999.999: "$999.99M",
+	} {
+		require.Equal(t, expected, USD.Compact(amount), amount)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	require.Equal(t, "+10.08%", Percent(10.0829076670949820))
+	require.Equal(t, "-3.50%", Percent(-3.5))
+	require.Equal(t, "0.00%", Percent(0))
+	require.Equal(t, "0.00%", Percent(-0.001))
+	require.Equal(t, "0.00%", Percent(0.004))
+	require.Equal(t, "-0.01%", Percent(-0.005001))
+}
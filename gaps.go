@@ -0,0 +1,198 @@
+package coincap
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type Gap struct {
+	From    time.Time // first missing period
+	To      time.Time // last missing period
+	Missing int       // number of missing periods
+}
+
+type FillMethod int
+
+const (
+	ForwardFill FillMethod = iota // repeat the previous value, candles get zero volume
+	LinearFill                    // interpolate between the surrounding values
+	NaNFill                       // insert rows with NaN values as explicit markers
+)
+
+// HistoryGaps lists the missing periods of history against the Interval and,
+// when both are set, the Start/End of p. Otherwise the range of history is used.
+func (p HistoryParams) HistoryGaps(history []AssetHistory) []Gap {
+	return p.gaps(historyTimes(history))
+}
+
+func (p HistoryParams) CandleGaps(candles []Candle) []Gap {
+	times := make([]int64, len(candles))
+	for i, c := range candles {
+		times[i] = c.Period
+	}
+	return p.gaps(times)
+}
+
+// FillHistory inserts a row for every missing period. Missing periods before
+// the first row are always NaN markers, after the last row LinearFill
+// falls back to ForwardFill.
+func (p HistoryParams) FillHistory(history []AssetHistory, method FillMethod) []AssetHistory {
+	sorted := append([]AssetHistory(nil), history...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	var out []AssetHistory
+	byTime := make(map[int64]AssetHistory, len(sorted))
+	for _, h := range sorted {
+		byTime[h.Time] = h
+	}
+	next := 0
+	for _, t := range p.grid(historyTimes(sorted)) {
+		for next < len(sorted) && sorted[next].Time < t {
+			out = appendHistory(out, sorted[next])
+			next++
+		}
+		if h, ok := byTime[t]; ok {
+			out = appendHistory(out, h)
+			next++
+			continue
+		}
+		row := AssetHistory{PriceUsd: math.NaN(), CirculatingSupply: math.NaN(), Time: t, Date: time.UnixMilli(t).UTC()}
+		if len(out) > 0 && method != NaNFill {
+			prev := out[len(out)-1]
+			row.PriceUsd, row.CirculatingSupply = prev.PriceUsd, prev.CirculatingSupply
+			if method == LinearFill && next < len(sorted) {
+				w := weight(prev.Time, sorted[next].Time, t)
+				row.PriceUsd = lerp(prev.PriceUsd, sorted[next].PriceUsd, w)
+				row.CirculatingSupply = lerp(prev.CirculatingSupply, sorted[next].CirculatingSupply, w)
+			}
+		}
+		out = append(out, row)
+	}
+	for ; next < len(sorted); next++ {
+		out = appendHistory(out, sorted[next])
+	}
+	return out
+}
+
+// FillCandles inserts a zero volume candle for every missing period, see
+// FillHistory. ForwardFill repeats the previous close as a flat candle,
+// LinearFill moves from the previous close towards the next open.
+func (p HistoryParams) FillCandles(candles []Candle, method FillMethod) []Candle {
+	sorted := append([]Candle(nil), candles...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Period < sorted[j].Period })
+	times := make([]int64, len(sorted))
+	byTime := make(map[int64]Candle, len(sorted))
+	for i, c := range sorted {
+		times[i] = c.Period
+		byTime[c.Period] = c
+	}
+	var out []Candle
+	next := 0
+	nan := math.NaN()
+	for _, t := range p.grid(times) {
+		for next < len(sorted) && sorted[next].Period < t {
+			out = appendCandle(out, sorted[next])
+			next++
+		}
+		if c, ok := byTime[t]; ok {
+			out = appendCandle(out, c)
+			next++
+			continue
+		}
+		row := Candle{Open: nan, High: nan, Low: nan, Close: nan, Volume: nan, Period: t}
+		if len(out) > 0 && method != NaNFill {
+			prev := out[len(out)-1]
+			row = Candle{Open: prev.Close, High: prev.Close, Low: prev.Close, Close: prev.Close, Period: t}
+			if method == LinearFill && next < len(sorted) {
+				row.Close = lerp(prev.Close, sorted[next].Open, weight(prev.Period, sorted[next].Period, t))
+				row.High, row.Low = math.Max(row.Open, row.Close), math.Min(row.Open, row.Close)
+			}
+		}
+		out = append(out, row)
+	}
+	for ; next < len(sorted); next++ {
+		out = appendCandle(out, sorted[next])
+	}
+	return out
+}
+
+// grid lists the expected periods, aligned to the interval like CoinCap does.
+func (p HistoryParams) grid(times []int64) []int64 {
+	step := p.Interval.Value().Milliseconds()
+	if step == 0 {
+		return nil
+	}
+	var first, last int64
+	if !p.Start.IsZero() && !p.End.IsZero() {
+		first, last = p.Start.UnixMilli(), p.End.UnixMilli()-1
+	} else {
+		if len(times) == 0 {
+			return nil
+		}
+		first, last = times[0], times[len(times)-1]
+		for _, t := range times {
+			if t < first {
+				first = t
+			}
+			if t > last {
+				last = t
+			}
+		}
+	}
+	first += floorMod(-first, step)
+	var grid []int64
+	for t := first; t <= last; t += step {
+		grid = append(grid, t)
+	}
+	return grid
+}
+
+func (p HistoryParams) gaps(times []int64) []Gap {
+	present := make(map[int64]struct{}, len(times))
+	for _, t := range times {
+		present[t] = struct{}{}
+	}
+	step := p.Interval.Value().Milliseconds()
+	var gaps []Gap
+	for _, t := range p.grid(times) {
+		if _, ok := present[t]; ok {
+			continue
+		}
+		if n := len(gaps); n > 0 && gaps[n-1].To.UnixMilli()+step == t {
+			gaps[n-1].To = time.UnixMilli(t).UTC()
+			gaps[n-1].Missing++
+			continue
+		}
+		gaps = append(gaps, Gap{From: time.UnixMilli(t).UTC(), To: time.UnixMilli(t).UTC(), Missing: 1})
+	}
+	return gaps
+}
+
+func historyTimes(history []AssetHistory) []int64 {
+	times := make([]int64, len(history))
+	for i, h := range history {
+		times[i] = h.Time
+	}
+	return times
+}
+
+// appendHistory skips duplicates of the last row's time.
+func appendHistory(out []AssetHistory, h AssetHistory) []AssetHistory {
+	if n := len(out); n > 0 && out[n-1].Time == h.Time {
+		return out
+	}
+	return append(out, h)
+}
+
+func appendCandle(out []Candle, c Candle) []Candle {
+	if n := len(out); n > 0 && out[n-1].Period == c.Period {
+		return out
+	}
+	return append(out, c)
+}
+
+func weight(from, to, t int64) float64 {
+	return float64(t-from) / float64(to-from)
+}
+
+func lerp(a, b, w float64) float64 { return a + (b-a)*w }
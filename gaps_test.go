@@ -0,0 +1,83 @@
+package coincap
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryParams_Gaps(t *testing.T) {
+	var data AssetHistoriesData
+	require.NoError(t, unmarshalModel("asset_history", &data))
+	p := HistoryParams{Interval: M30}
+	require.Empty(t, p.HistoryGaps(data.Data))
+
+	history := append([]AssetHistory{data.Data[0]}, data.Data[3:]...)
+	gaps := p.HistoryGaps(history)
+	require.Equal(t, []Gap{{From: data.Data[1].Date, To: data.Data[2].Date, Missing: 2}}, gaps)
+
+	first := data.Data[0].Date
+	p = HistoryParams{Interval: M30, Start: first.Add(-time.Hour), End: first.Add(time.Hour * 3)}
+	gaps = p.HistoryGaps(data.Data)
+	require.Len(t, gaps, 2)
+	require.Equal(t, Gap{From: first.Add(-time.Hour), To: first.Add(-time.Minute * 30), Missing: 2}, gaps[0])
+	require.Equal(t, Gap{From: first.Add(time.Hour * 2).Add(time.Minute * 30), To: first.Add(time.Hour * 2).Add(time.Minute * 30), Missing: 1}, gaps[1])
+
+	var candles CandlesData
+	require.NoError(t, unmarshalModel("candles", &candles))
+	require.Empty(t, HistoryParams{Interval: M30}.CandleGaps(candles.Data))
+
+	require.Empty(t, HistoryParams{Interval: M1, Start: first}.HistoryGaps(nil))
+	require.Empty(t, HistoryParams{Interval: M1, End: first}.FillCandles(nil, LinearFill))
+	require.Len(t, HistoryParams{Interval: M30, Start: first, End: first.Add(time.Hour)}.HistoryGaps(nil), 1)
+}
+
+func TestHistoryParams_FillHistory(t *testing.T) {
+	at := func(minutes int) int64 { return t1.Add(time.Minute * time.Duration(minutes)).UnixMilli() }
+	history := []AssetHistory{
+		{PriceUsd: 40, CirculatingSupply: 400, Time: at(60)},
+		{PriceUsd: 10, CirculatingSupply: 100, Time: at(0)},
+	}
+	p := HistoryParams{Interval: M15, Start: t1.Add(-time.Minute * 15), End: t1.Add(time.Minute * 90)}
+
+	prices := func(h []AssetHistory) []float64 {
+		v := make([]float64, len(h))
+		for i := range h {
+			v[i] = h[i].PriceUsd
+		}
+		return v
+	}
+	linear := p.FillHistory(history, LinearFill)
+	require.Len(t, linear, 7)
+	require.True(t, math.IsNaN(linear[0].PriceUsd))
+	require.Equal(t, []float64{10, 17.5, 25, 32.5, 40, 40}, prices(linear[1:]))
+	require.Equal(t, 175.0, linear[2].CirculatingSupply)
+	require.Equal(t, at(15), linear[2].Time)
+	require.Equal(t, time.UnixMilli(at(15)).UTC(), linear[2].Date)
+
+	require.Equal(t, []float64{10, 10, 10, 10, 40, 40}, prices(p.FillHistory(history, ForwardFill)[1:]))
+	nanFilled := p.FillHistory(history, NaNFill)
+	require.True(t, math.IsNaN(nanFilled[2].PriceUsd))
+	require.Equal(t, 40.0, nanFilled[5].PriceUsd)
+}
+
+func TestHistoryParams_FillCandles(t *testing.T) {
+	candles := []Candle{
+		{Open: 1, High: 2, Low: 1, Close: 2, Volume: 5, Period: t1.UnixMilli()},
+		{Open: 5, High: 6, Low: 4, Close: 5, Volume: 5, Period: t1.Add(time.Hour * 3).UnixMilli()},
+	}
+	p := HistoryParams{Interval: H1}
+	forward := p.FillCandles(candles, ForwardFill)
+	require.Len(t, forward, 4)
+	require.Equal(t, Candle{Open: 2, High: 2, Low: 2, Close: 2, Period: t1.Add(time.Hour).UnixMilli()}, forward[1])
+
+	linear := p.FillCandles(candles, LinearFill)
+	require.Equal(t, Candle{Open: 2, High: 3, Low: 2, Close: 3, Period: t1.Add(time.Hour).UnixMilli()}, linear[1])
+	require.Equal(t, Candle{Open: 3, High: 4, Low: 3, Close: 4, Period: t1.Add(time.Hour * 2).UnixMilli()}, linear[2])
+
+	nanFilled := p.FillCandles(candles, NaNFill)
+	require.True(t, math.IsNaN(nanFilled[1].Close))
+	require.True(t, math.IsNaN(nanFilled[2].Volume))
+}
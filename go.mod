@@ -2,7 +2,10 @@ module github.com/esenmx/coincap-go
 
 go 1.18
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/gorilla/websocket v1.5.0
+	github.com/stretchr/testify v1.7.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
@@ -0,0 +1,413 @@
+package coincap
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type OverflowPolicy int
+
+const (
+	Drop     OverflowPolicy = iota // discard new items while the buffer is full
+	Block                          // wait for the subscriber, stalling every other subscriber
+	Conflate                       // keep only the latest item per asset while the buffer is full
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case Drop:
+		return "drop"
+	case Block:
+		return "block"
+	case Conflate:
+		return "conflate"
+	default:
+		return ""
+	}
+}
+
+type SubscriberConfig struct {
+	Name   string         // optional, used in slow consumer reports
+	Assets []string       // optional, asset ids to receive. every asset when empty
+	Buffer int            // optional, channel capacity, 64 when zero
+	Policy OverflowPolicy // optional, Drop by default
+}
+
+type SlowConsumer struct {
+	Name     string
+	Policy   OverflowPolicy
+	Dropped  uint64 // items dropped or conflated so far, always zero for Block
+	Buffered int
+}
+
+type SlowConsumerCallback func(SlowConsumer)
+
+type HubOption func(*hubConfig)
+
+type hubConfig struct {
+	slow SlowConsumerCallback
+}
+
+// WithSlowConsumer is called whenever a subscriber's buffer overflows after
+// having been drained since its previous report. It runs on the dispatch goroutine.
+func WithSlowConsumer(cb SlowConsumerCallback) HubOption { return func(c *hubConfig) { c.slow = cb } }
+
+// Hub shares one upstream stream between many in-process subscribers.
+type Hub[T any] struct {
+	config   hubConfig
+	source   <-chan T
+	run      func(ctx context.Context) error
+	assets   func(T) []string
+	key      func(T) string
+	upstream func(assets []string)
+	mu       sync.Mutex
+	subs     map[*Subscription[T]]struct{}
+}
+
+// NewHub dispatches items of source. run, if not nil, is started by Hub.Run
+// and should feed source. assets lists the asset ids an item concerns and key
+// identifies items replacing each other under Conflate.
+func NewHub[T any](source <-chan T, run func(context.Context) error, assets func(T) []string, key func(T) string, options ...HubOption) *Hub[T] {
+	h := &Hub[T]{source: source, run: run, assets: assets, key: key, subs: map[*Subscription[T]]struct{}{}}
+	for _, option := range options {
+		option(&h.config)
+	}
+	return h
+}
+
+// NewPriceHub owns s and keeps its subscription to the union of the subscriber assets.
+func NewPriceHub(s *PriceStream, options ...HubOption) *Hub[PriceUpdate] {
+	h := NewHub(s.Updates(), s.Run,
+		func(u PriceUpdate) []string { return []string{u.Id} },
+		func(u PriceUpdate) string { return u.Id },
+		options...)
+	h.upstream = func(assets []string) {
+		if strings.Join(assets, ",") != strings.Join(s.Assets(), ",") {
+			s.Subscribe(assets...)
+		}
+	}
+	return h
+}
+
+// NewTradeHub owns s, subscriber assets match either side of a trade's pair.
+func NewTradeHub(s *TradeStream, options ...HubOption) *Hub[Trade] {
+	return NewHub(s.Trades(), s.Run,
+		func(t Trade) []string { return []string{t.Base, t.Quote} },
+		func(t Trade) string { return t.Base + "/" + t.Quote },
+		options...)
+}
+
+// Run dispatches until the source is closed or ctx is done, then closes every subscription.
+func (h *Hub[T]) Run(ctx context.Context) error {
+	defer h.closeAll()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 1)
+	if h.run != nil {
+		go func() { errs <- h.run(ctx) }()
+	}
+	for {
+		select {
+		case v, ok := <-h.source:
+			if !ok {
+				if h.run != nil {
+					return <-errs
+				}
+				return nil
+			}
+			h.dispatch(ctx, v)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (h *Hub[T]) Subscribe(config SubscriberConfig) *Subscription[T] {
+	if config.Buffer <= 0 {
+		config.Buffer = 64
+	}
+	s := &Subscription[T]{hub: h, config: config, ch: make(chan T, config.Buffer), done: make(chan struct{})}
+	s.assets = assetSet(config.Assets)
+	if config.Policy == Conflate {
+		s.pending = map[string]T{}
+		s.signal = make(chan struct{}, 1)
+		go s.pump()
+	}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	h.changed()
+	return s
+}
+
+// SlowConsumers reports subscribers with items currently buffered at capacity.
+func (h *Hub[T]) SlowConsumers() []SlowConsumer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var slow []SlowConsumer
+	for s := range h.subs {
+		if r := s.report(); r.Buffered >= s.config.Buffer {
+			slow = append(slow, r)
+		}
+	}
+	sort.Slice(slow, func(i, j int) bool { return slow[i].Name < slow[j].Name })
+	return slow
+}
+
+func (h *Hub[T]) dispatch(ctx context.Context, v T) {
+	assets := h.assets(v)
+	h.mu.Lock()
+	subs := make([]*Subscription[T], 0, len(h.subs))
+	for s := range h.subs {
+		if s.match(assets) {
+			subs = append(subs, s)
+		}
+	}
+	h.mu.Unlock()
+	for _, s := range subs {
+		s.offer(ctx, v)
+	}
+}
+
+// changed points the upstream at the union of the subscriber assets. Without
+// subscribers the current upstream is kept.
+func (h *Hub[T]) changed() {
+	if h.upstream == nil {
+		return
+	}
+	h.mu.Lock()
+	if len(h.subs) == 0 {
+		h.mu.Unlock()
+		return
+	}
+	all := false
+	union := map[string]struct{}{}
+	for s := range h.subs {
+		s.mu.Lock()
+		all = all || s.assets == nil
+		for id := range s.assets {
+			union[id] = struct{}{}
+		}
+		s.mu.Unlock()
+	}
+	h.mu.Unlock()
+	if all {
+		h.upstream([]string{AllAssets})
+		return
+	}
+	assets := make([]string, 0, len(union))
+	for id := range union {
+		assets = append(assets, id)
+	}
+	sort.Strings(assets)
+	h.upstream(assets)
+}
+
+func (h *Hub[T]) remove(s *Subscription[T]) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+}
+
+func (h *Hub[T]) closeAll() {
+	h.mu.Lock()
+	subs := make([]*Subscription[T], 0, len(h.subs))
+	for s := range h.subs {
+		subs = append(subs, s)
+	}
+	h.mu.Unlock()
+	for _, s := range subs {
+		s.close(false)
+	}
+}
+
+type Subscription[T any] struct {
+	hub       *Hub[T]
+	config    SubscriberConfig
+	ch        chan T
+	done      chan struct{}
+	closeOnce sync.Once
+	sendMu    sync.Mutex
+	closed    bool
+	dropped   uint64
+	slow      int32
+
+	mu       sync.Mutex
+	assets   map[string]struct{}
+	pending  map[string]T
+	order    []string
+	inflight bool
+	signal   chan struct{}
+}
+
+// Updates is closed once the subscription or the hub is closed.
+func (s *Subscription[T]) Updates() <-chan T { return s.ch }
+
+func (s *Subscription[T]) Dropped() uint64 { return atomic.LoadUint64(&s.dropped) }
+
+// SetAssets replaces the asset filter, every asset when empty.
+func (s *Subscription[T]) SetAssets(assets ...string) {
+	s.mu.Lock()
+	s.assets = assetSet(assets)
+	s.mu.Unlock()
+	s.hub.changed()
+}
+
+func (s *Subscription[T]) Close() { s.close(true) }
+
+func (s *Subscription[T]) close(notify bool) {
+	s.closeOnce.Do(func() {
+		// Conflate offers never block, and pump closes ch once done is closed.
+		if s.config.Policy == Conflate {
+			s.sendMu.Lock()
+			s.closed = true
+			s.sendMu.Unlock()
+			close(s.done)
+		} else {
+			close(s.done)
+			s.sendMu.Lock()
+			s.closed = true
+			close(s.ch)
+			s.sendMu.Unlock()
+		}
+		s.hub.remove(s)
+		if notify {
+			s.hub.changed()
+		}
+	})
+}
+
+func (s *Subscription[T]) match(assets []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.assets == nil {
+		return true
+	}
+	for _, id := range assets {
+		if _, ok := s.assets[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Subscription[T]) offer(ctx context.Context, v T) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.closed {
+		return
+	}
+	switch s.config.Policy {
+	case Block:
+		select {
+		case s.ch <- v:
+			s.healthy()
+			return
+		default:
+		}
+		s.overflow(false)
+		select {
+		case s.ch <- v:
+		case <-s.done:
+		case <-ctx.Done():
+		}
+	case Conflate:
+		s.mu.Lock()
+		if len(s.order) == 0 && !s.inflight {
+			select {
+			case s.ch <- v:
+				s.mu.Unlock()
+				s.healthy()
+				return
+			default:
+			}
+		}
+		key := s.hub.key(v)
+		if _, ok := s.pending[key]; ok {
+			s.mu.Unlock()
+			s.overflow(true)
+			s.mu.Lock()
+		} else {
+			s.order = append(s.order, key)
+		}
+		s.pending[key] = v
+		s.mu.Unlock()
+		select {
+		case s.signal <- struct{}{}:
+		default:
+		}
+	default:
+		select {
+		case s.ch <- v:
+			s.healthy()
+		default:
+			s.overflow(true)
+		}
+	}
+}
+
+// pump moves items that found ch full into it, in first-arrival order per key.
+// While it holds an item, offer queues behind it to keep the order.
+func (s *Subscription[T]) pump() {
+	defer close(s.ch)
+	for {
+		s.mu.Lock()
+		if len(s.order) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.signal:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		key := s.order[0]
+		v := s.pending[key]
+		s.order = s.order[1:]
+		delete(s.pending, key)
+		s.inflight = true
+		s.mu.Unlock()
+		select {
+		case s.ch <- v:
+		case <-s.done:
+			return
+		}
+		s.mu.Lock()
+		s.inflight = false
+		s.mu.Unlock()
+		s.healthy()
+	}
+}
+
+func (s *Subscription[T]) healthy() {
+	if len(s.ch) == 0 {
+		atomic.StoreInt32(&s.slow, 0)
+	}
+}
+
+func (s *Subscription[T]) overflow(dropped bool) {
+	if dropped {
+		atomic.AddUint64(&s.dropped, 1)
+	}
+	if atomic.CompareAndSwapInt32(&s.slow, 0, 1) && s.hub.config.slow != nil {
+		s.hub.config.slow(s.report())
+	}
+}
+
+func (s *Subscription[T]) report() SlowConsumer {
+	return SlowConsumer{Name: s.config.Name, Policy: s.config.Policy, Dropped: s.Dropped(), Buffered: len(s.ch)}
+}
+
+func assetSet(assets []string) map[string]struct{} {
+	if len(assets) == 0 {
+		return nil
+	}
+	m := make(map[string]struct{}, len(assets))
+	for _, id := range assets {
+		m[id] = struct{}{}
+	}
+	return m
+}
@@ -0,0 +1,119 @@
+package coincap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func priceHub(source chan PriceUpdate, options ...HubOption) *Hub[PriceUpdate] {
+	return NewHub[PriceUpdate](source, nil,
+		func(u PriceUpdate) []string { return []string{u.Id} },
+		func(u PriceUpdate) string { return u.Id },
+		options...)
+}
+
+func TestHub_Policies(t *testing.T) {
+	source := make(chan PriceUpdate)
+	var reports []SlowConsumer
+	h := priceHub(source, WithSlowConsumer(func(c SlowConsumer) { reports = append(reports, c) }))
+	all := h.Subscribe(SubscriberConfig{Name: "all", Buffer: 10})
+	dropping := h.Subscribe(SubscriberConfig{Name: "drop", Assets: []string{"bitcoin"}, Buffer: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() { errs <- h.Run(ctx) }()
+
+	for _, u := range []PriceUpdate{{Id: "bitcoin", PriceUsd: 1}, {Id: "bitcoin", PriceUsd: 2}, {Id: "solana", PriceUsd: 3}, {Id: "bitcoin", PriceUsd: 4}, {Id: "ethereum", PriceUsd: 5}} {
+		source <- u
+	}
+	require.Eventually(t, func() bool { return len(all.Updates()) == 5 }, time.Second, time.Millisecond)
+
+	require.Equal(t, 1.0, (<-dropping.Updates()).PriceUsd)
+	require.Equal(t, uint64(2), dropping.Dropped())
+	require.Equal(t, SlowConsumer{Name: "drop", Policy: Drop, Dropped: 1, Buffered: 1}, reports[0])
+
+	dropping.Close()
+	_, ok := <-dropping.Updates()
+	require.False(t, ok)
+
+	close(source)
+	require.NoError(t, <-errs)
+	require.Len(t, all.Updates(), 5)
+	for range all.Updates() {
+	}
+}
+
+func TestHub_Conflate(t *testing.T) {
+	source := make(chan PriceUpdate)
+	h := priceHub(source)
+	conflating := h.Subscribe(SubscriberConfig{Name: "conflate", Assets: []string{"bitcoin", "ethereum"}, Buffer: 1, Policy: Conflate})
+	queued := func() int {
+		conflating.mu.Lock()
+		defer conflating.mu.Unlock()
+		return len(conflating.order)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() { errs <- h.Run(ctx) }()
+
+	// 1 fills the buffer and the pump holds 2 until the consumer reads
+	source <- PriceUpdate{Id: "bitcoin", PriceUsd: 1}
+	source <- PriceUpdate{Id: "bitcoin", PriceUsd: 2}
+	require.Eventually(t, func() bool {
+		conflating.mu.Lock()
+		defer conflating.mu.Unlock()
+		return conflating.inflight
+	}, time.Second, time.Millisecond)
+
+	// 3 waits behind the pump and 4 replaces it
+	for _, u := range []PriceUpdate{{Id: "bitcoin", PriceUsd: 3}, {Id: "bitcoin", PriceUsd: 4}, {Id: "ethereum", PriceUsd: 5}} {
+		source <- u
+	}
+	require.Eventually(t, func() bool { return queued() == 2 }, time.Second, time.Millisecond)
+	require.Equal(t, uint64(1), conflating.Dropped())
+
+	var received []float64
+	for range []int{1, 2, 3, 4} {
+		received = append(received, (<-conflating.Updates()).PriceUsd)
+	}
+	require.Equal(t, []float64{1, 2, 4, 5}, received)
+	require.Equal(t, uint64(1), conflating.Dropped())
+
+	close(source)
+	require.NoError(t, <-errs)
+	_, ok := <-conflating.Updates()
+	require.False(t, ok)
+}
+
+func TestHub_Block(t *testing.T) {
+	source := make(chan PriceUpdate)
+	h := priceHub(source)
+	blocking := h.Subscribe(SubscriberConfig{Name: "block", Buffer: 1, Policy: Block})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	go func() { _ = h.Run(ctx) }()
+
+	source <- PriceUpdate{Id: "bitcoin", PriceUsd: 1}
+	source <- PriceUpdate{Id: "bitcoin", PriceUsd: 2}
+	require.Eventually(t, func() bool { return len(h.SlowConsumers()) == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, 1.0, (<-blocking.Updates()).PriceUsd)
+	require.Equal(t, 2.0, (<-blocking.Updates()).PriceUsd)
+	require.Zero(t, blocking.Dropped())
+}
+
+func TestPriceHub_Upstream(t *testing.T) {
+	s := NewPriceStream([]string{"bitcoin"})
+	h := NewPriceHub(s)
+	a := h.Subscribe(SubscriberConfig{Assets: []string{"solana", "bitcoin"}})
+	h.Subscribe(SubscriberConfig{Assets: []string{"ethereum"}})
+	require.Equal(t, []string{"bitcoin", "ethereum", "solana"}, s.Assets())
+	a.SetAssets()
+	require.Equal(t, []string{AllAssets}, s.Assets())
+	a.Close()
+	require.Equal(t, []string{"ethereum"}, s.Assets())
+}
@@ -0,0 +1,105 @@
+// Package indicators computes technical indicators over CoinCap candles and
+// price history. Batch functions return a slice aligned with their input,
+// NaN until the indicator has enough data, which is never for a period below
+// 1. Every indicator has a streaming counterpart for live data, which should
+// be fed finalized candles only.
+package indicators
+
+import (
+	"math"
+
+	"github.com/esenmx/coincap-go"
+)
+
+func Closes(candles []coincap.Candle) []float64 {
+	values := make([]float64, len(candles))
+	for i, c := range candles {
+		values[i] = c.Close
+	}
+	return values
+}
+
+func Prices(history []coincap.AssetHistory) []float64 {
+	values := make([]float64, len(history))
+	for i, h := range history {
+		values[i] = h.PriceUsd
+	}
+	return values
+}
+
+// SMA is the simple moving average over period values.
+func SMA(values []float64, period int) []float64 {
+	return apply(values, NewSMAStream(period).Update)
+}
+
+// EMA is the exponential moving average with alpha 2/(period+1), seeded with the SMA.
+func EMA(values []float64, period int) []float64 {
+	return apply(values, NewEMAStream(period).Update)
+}
+
+// RSI is Wilder's relative strength index.
+func RSI(values []float64, period int) []float64 {
+	return apply(values, NewRSIStream(period).Update)
+}
+
+// MACD returns the MACD line, its signal line and the histogram. The MACD
+// line starts once both EMAs are ready, the others once the signal is.
+func MACD(values []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	s := NewMACDStream(fast, slow, signal)
+	macd, signalLine, histogram = nanSlice(len(values)), nanSlice(len(values)), nanSlice(len(values))
+	for i, v := range values {
+		m, _ := s.Update(v)
+		macd[i], signalLine[i], histogram[i] = m.MACD, m.Signal, m.Histogram
+	}
+	return macd, signalLine, histogram
+}
+
+// Bollinger returns the bands k population standard deviations around the SMA.
+func Bollinger(values []float64, period int, k float64) (middle, upper, lower []float64) {
+	s := NewBollingerStream(period, k)
+	middle, upper, lower = nanSlice(len(values)), nanSlice(len(values)), nanSlice(len(values))
+	for i, v := range values {
+		if b, ok := s.Update(v); ok {
+			middle[i], upper[i], lower[i] = b.Middle, b.Upper, b.Lower
+		}
+	}
+	return middle, upper, lower
+}
+
+// ATR is Wilder's average true range.
+func ATR(candles []coincap.Candle, period int) []float64 {
+	return applyCandles(candles, NewATRStream(period).Update)
+}
+
+// VWAP is the cumulative volume weighted average of the typical price (H+L+C)/3.
+func VWAP(candles []coincap.Candle) []float64 {
+	return applyCandles(candles, NewVWAPStream().Update)
+}
+
+func apply(values []float64, update func(float64) (float64, bool)) []float64 {
+	out := nanSlice(len(values))
+	for i, v := range values {
+		if r, ok := update(v); ok {
+			out[i] = r
+		}
+	}
+	return out
+}
+
+func applyCandles(candles []coincap.Candle, update func(coincap.Candle) (float64, bool)) []float64 {
+	out := nanSlice(len(candles))
+	for i, c := range candles {
+		if r, ok := update(c); ok {
+			out[i] = r
+		}
+	}
+	return out
+}
+
+func nanSlice(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+package indicators
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+// Wilder's RSI example from the StockCharts spreadsheet.
+var rsiCloses = []float64{
+	44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+	45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+}
+
+func requireSeries(t *testing.T, expected, actual []float64, delta float64) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		if math.IsNaN(expected[i]) {
+			require.True(t, math.IsNaN(actual[i]), "index %d: %v", i, actual[i])
+			continue
+		}
+		require.InDelta(t, expected[i], actual[i], delta, "index %d", i)
+	}
+}
+
+var nan = math.NaN()
+
+func TestSMA_EMA(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6}
+	requireSeries(t, []float64{nan, nan, 2, 3, 4, 5}, SMA(values, 3), 1e-12)
+	// alpha 0.5, seeded with the SMA of the first three values
+	requireSeries(t, []float64{nan, nan, 2, 3, 4, 5}, EMA(values, 3), 1e-12)
+	requireSeries(t, []float64{nan, nan, 2, 6, 4.5, 2.25}, EMA([]float64{1, 2, 3, 10, 3, 0}, 3), 1e-12)
+}
+
+func TestRSI(t *testing.T) {
+	rsi := RSI(rsiCloses, 14)
+	for i := 0; i < 14; i++ {
+		require.True(t, math.IsNaN(rsi[i]))
+	}
+	requireSeries(t, []float64{70.46, 66.25, 66.48, 69.35, 66.29, 57.92}, rsi[14:], 0.01)
+	requireSeries(t, []float64{nan, nan, 100, 100}, RSI([]float64{1, 2, 3, 4}, 2), 1e-12)
+}
+
+func TestMACD(t *testing.T) {
+	macd, signal, histogram := MACD(rsiCloses, 3, 6, 4)
+	fast, slow := EMA(rsiCloses, 3), EMA(rsiCloses, 6)
+	for i := range rsiCloses {
+		if i < 5 {
+			require.True(t, math.IsNaN(macd[i]))
+			continue
+		}
+		require.InDelta(t, fast[i]-slow[i], macd[i], 1e-12)
+	}
+	requireSeries(t, EMA(macd[5:], 4), signal[5:], 1e-12)
+	require.True(t, math.IsNaN(histogram[7]))
+	require.InDelta(t, macd[8]-signal[8], histogram[8], 1e-12)
+}
+
+func TestBollinger(t *testing.T) {
+	middle, upper, lower := Bollinger([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+	// population standard deviation of the series is 2
+	require.Equal(t, 5.0, middle[7])
+	require.InDelta(t, 9, upper[7], 1e-12)
+	require.InDelta(t, 1, lower[7], 1e-12)
+	require.True(t, math.IsNaN(middle[6]))
+}
+
+func TestATR_VWAP(t *testing.T) {
+	candles := []coincap.Candle{
+		{High: 10, Low: 8, Close: 9, Volume: 1},
+		{High: 12, Low: 9, Close: 11, Volume: 2},  // true range 3
+		{High: 11, Low: 10, Close: 10, Volume: 1}, // true range 1
+		{High: 15, Low: 13, Close: 14, Volume: 0}, // true range 5, gap from 10
+	}
+	// (2+3)/2 = 2.5, then (2.5+1)/2 = 1.75, then (1.75+5)/2 = 3.375
+	requireSeries(t, []float64{nan, 2.5, 1.75, 3.375}, ATR(candles, 2), 1e-12)
+	// typical prices 9, 32/3, 31/3
+	requireSeries(t, []float64{9, (9 + 64.0/3) / 3, (9 + 64.0/3 + 31.0/3) / 4, (9 + 64.0/3 + 31.0/3) / 4}, VWAP(candles), 1e-12)
+}
+
+func TestStreams_MatchBatch(t *testing.T) {
+	bs, err := os.ReadFile("../mock/candles.json")
+	require.NoError(t, err)
+	var data coincap.CandlesData
+	require.NoError(t, json.Unmarshal(bs, &data))
+	closes := Closes(data.Data)
+	sma, ema, rsi, atr := NewSMAStream(3), NewEMAStream(3), NewRSIStream(3), NewATRStream(3)
+	batchSMA, batchEMA, batchRSI, batchATR := SMA(closes, 3), EMA(closes, 3), RSI(closes, 3), ATR(data.Data, 3)
+	for i, c := range data.Data {
+		for _, pair := range []struct {
+			update func() (float64, bool)
+			batch  float64
+		}{
+			{func() (float64, bool) { return sma.Update(c.Close) }, batchSMA[i]},
+			{func() (float64, bool) { return ema.Update(c.Close) }, batchEMA[i]},
+			{func() (float64, bool) { return rsi.Update(c.Close) }, batchRSI[i]},
+			{func() (float64, bool) { return atr.Update(c) }, batchATR[i]},
+		} {
+			v, ok := pair.update()
+			require.Equal(t, !math.IsNaN(pair.batch), ok)
+			if ok {
+				require.Equal(t, pair.batch, v)
+			}
+		}
+	}
+	require.Equal(t, []float64{1, 2}, Prices([]coincap.AssetHistory{{PriceUsd: 1}, {PriceUsd: 2}}))
+}
+
+func TestInvalidPeriod(t *testing.T) {
+	values := []float64{1, 2, 3, 4}
+	candles := []coincap.Candle{{High: 2, Low: 1, Close: 1.5}, {High: 3, Low: 2, Close: 2.5}}
+	none := []float64{nan, nan, nan, nan}
+	for _, period := range []int{0, -1} {
+		requireSeries(t, none, SMA(values, period), 0)
+		requireSeries(t, none, EMA(values, period), 0)
+		requireSeries(t, none, RSI(values, period), 0)
+		requireSeries(t, none[:2], ATR(candles, period), 0)
+		middle, upper, lower := Bollinger(values, period, 2)
+		requireSeries(t, none, middle, 0)
+		requireSeries(t, none, upper, 0)
+		requireSeries(t, none, lower, 0)
+		macd, signal, histogram := MACD(values, period, 2, 2)
+		requireSeries(t, none, macd, 0)
+		requireSeries(t, none, signal, 0)
+		requireSeries(t, none, histogram, 0)
+		_, ok := NewMACDStream(1, 2, period).Update(1)
+		require.False(t, ok)
+	}
+}
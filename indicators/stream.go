@@ -0,0 +1,223 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/esenmx/coincap-go"
+)
+
+// SMAStream keeps the last period values. Update reports false until it has
+// period values, so forever when period is below 1.
+type SMAStream struct {
+	period int
+	window []float64
+	next   int
+	sum    float64
+	count  int
+}
+
+func NewSMAStream(period int) *SMAStream {
+	if period < 0 {
+		period = 0
+	}
+	return &SMAStream{period: period, window: make([]float64, period)}
+}
+
+func (s *SMAStream) Update(v float64) (float64, bool) {
+	if s.period == 0 {
+		return 0, false
+	}
+	if s.count == s.period {
+		s.sum -= s.window[s.next]
+	} else {
+		s.count++
+	}
+	s.window[s.next] = v
+	s.sum += v
+	s.next = (s.next + 1) % s.period
+	if s.count < s.period {
+		return 0, false
+	}
+	return s.sum / float64(s.period), true
+}
+
+type EMAStream struct {
+	alpha float64
+	seed  *SMAStream
+	value float64
+	ready bool
+}
+
+func NewEMAStream(period int) *EMAStream {
+	return &EMAStream{alpha: 2 / float64(period+1), seed: NewSMAStream(period)}
+}
+
+func (s *EMAStream) Update(v float64) (float64, bool) {
+	if !s.ready {
+		s.value, s.ready = s.seed.Update(v)
+		return s.value, s.ready
+	}
+	s.value += s.alpha * (v - s.value)
+	return s.value, true
+}
+
+// wilder is Wilder's smoothing, an EMA with alpha 1/period seeded with the SMA.
+// It never becomes ready when period is below 1.
+type wilder struct {
+	period int
+	count  int
+	value  float64
+}
+
+func (w *wilder) update(v float64) (float64, bool) {
+	if w.period < 1 {
+		return 0, false
+	}
+	if w.count < w.period {
+		w.count++
+		w.value += v / float64(w.period)
+		return w.value, w.count == w.period
+	}
+	w.value = (w.value*float64(w.period-1) + v) / float64(w.period)
+	return w.value, true
+}
+
+type RSIStream struct {
+	gain, loss wilder
+	last       float64
+	started    bool
+}
+
+func NewRSIStream(period int) *RSIStream {
+	return &RSIStream{gain: wilder{period: period}, loss: wilder{period: period}}
+}
+
+func (s *RSIStream) Update(v float64) (float64, bool) {
+	if !s.started {
+		s.last, s.started = v, true
+		return 0, false
+	}
+	change := v - s.last
+	s.last = v
+	gain, ok := s.gain.update(math.Max(change, 0))
+	loss, _ := s.loss.update(math.Max(-change, 0))
+	if !ok {
+		return 0, false
+	}
+	if loss == 0 {
+		if gain == 0 {
+			return 50, true
+		}
+		return 100, true
+	}
+	return 100 - 100/(1+gain/loss), true
+}
+
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+type MACDStream struct {
+	fast, slow, signal *EMAStream
+}
+
+func NewMACDStream(fast, slow, signal int) *MACDStream {
+	return &MACDStream{fast: NewEMAStream(fast), slow: NewEMAStream(slow), signal: NewEMAStream(signal)}
+}
+
+// Update returns the MACD line as soon as both EMAs are ready, with NaN
+// signal and histogram. It reports true once the signal line is ready too.
+func (s *MACDStream) Update(v float64) (MACDValue, bool) {
+	fast, fastOk := s.fast.Update(v)
+	slow, ok := s.slow.Update(v)
+	if !fastOk || !ok {
+		return MACDValue{MACD: math.NaN(), Signal: math.NaN(), Histogram: math.NaN()}, false
+	}
+	m := MACDValue{MACD: fast - slow, Signal: math.NaN(), Histogram: math.NaN()}
+	signal, ok := s.signal.Update(m.MACD)
+	if ok {
+		m.Signal, m.Histogram = signal, m.MACD-signal
+	}
+	return m, ok
+}
+
+type Bands struct {
+	Middle float64
+	Upper  float64
+	Lower  float64
+}
+
+type BollingerStream struct {
+	k      float64
+	sma    *SMAStream
+	sumSq  float64
+	window []float64
+	next   int
+	count  int
+}
+
+func NewBollingerStream(period int, k float64) *BollingerStream {
+	sma := NewSMAStream(period)
+	return &BollingerStream{k: k, sma: sma, window: make([]float64, sma.period)}
+}
+
+func (s *BollingerStream) Update(v float64) (Bands, bool) {
+	period := len(s.window)
+	if period == 0 {
+		return Bands{}, false
+	}
+	if s.count == period {
+		old := s.window[s.next]
+		s.sumSq -= old * old
+	} else {
+		s.count++
+	}
+	s.window[s.next] = v
+	s.sumSq += v * v
+	s.next = (s.next + 1) % period
+	mean, ok := s.sma.Update(v)
+	if !ok {
+		return Bands{}, false
+	}
+	sd := math.Sqrt(math.Max(s.sumSq/float64(period)-mean*mean, 0))
+	return Bands{Middle: mean, Upper: mean + s.k*sd, Lower: mean - s.k*sd}, true
+}
+
+type ATRStream struct {
+	tr        wilder
+	lastClose float64
+	started   bool
+}
+
+func NewATRStream(period int) *ATRStream {
+	return &ATRStream{tr: wilder{period: period}}
+}
+
+func (s *ATRStream) Update(c coincap.Candle) (float64, bool) {
+	tr := c.High - c.Low
+	if s.started {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-s.lastClose), math.Abs(c.Low-s.lastClose)))
+	}
+	s.lastClose, s.started = c.Close, true
+	return s.tr.update(tr)
+}
+
+type VWAPStream struct {
+	pv, volume float64
+}
+
+func NewVWAPStream() *VWAPStream { return &VWAPStream{} }
+
+func (s *VWAPStream) Update(c coincap.Candle) (float64, bool) {
+	s.pv += (c.High + c.Low + c.Close) / 3 * c.Volume
+	s.volume += c.Volume
+	if s.volume == 0 {
+		return 0, false
+	}
+	return s.pv / s.volume, true
+}
+
+// Reset starts a new session, e.g. at midnight UTC.
+func (s *VWAPStream) Reset() { s.pv, s.volume = 0, 0 }
@@ -0,0 +1,148 @@
+package coincap
+
+import (
+	"sort"
+	"time"
+)
+
+type Pair struct {
+	BaseId  string
+	QuoteId string
+}
+
+func (m Market) Pair() Pair { return Pair{BaseId: m.BaseId, QuoteId: m.QuoteId} }
+
+// PairMarkets are the markets of one pair across exchanges, cheapest first.
+type PairMarkets struct {
+	Pair
+	BaseSymbol  string
+	QuoteSymbol string
+	Markets     []Market
+}
+
+type Spread struct {
+	Low     Market  // cheapest market
+	High    Market  // most expensive market
+	Quote   float64 // High.PriceQuote - Low.PriceQuote
+	Percent float64 // Quote relative to Low.PriceQuote
+}
+
+// Fresh drops markets not updated within maxAge before the response
+// Timestamp, or before now when the timestamp is unset.
+func (d MarketsData) Fresh(maxAge time.Duration) MarketsData {
+	now := d.Timestamp
+	if now == 0 {
+		now = time.Now().UnixMilli()
+	}
+	oldest := now - maxAge.Milliseconds()
+	fresh := MarketsData{Timestamp: d.Timestamp}
+	for _, m := range d.Data {
+		if m.Updated >= oldest {
+			fresh.Data = append(fresh.Data, m)
+		}
+	}
+	return fresh
+}
+
+// Pairs groups the markets by base and quote id, ordered by their total
+// 24 hour volume. Markets without a quote price are skipped.
+func (d MarketsData) Pairs() []PairMarkets {
+	var pairs []PairMarkets
+	index := make(map[Pair]int)
+	for _, m := range d.Data {
+		if m.PriceQuote <= 0 {
+			continue
+		}
+		i, ok := index[m.Pair()]
+		if !ok {
+			i = len(pairs)
+			index[m.Pair()] = i
+			pairs = append(pairs, PairMarkets{Pair: m.Pair(), BaseSymbol: m.BaseSymbol, QuoteSymbol: m.QuoteSymbol})
+		}
+		pairs[i].Markets = append(pairs[i].Markets, m)
+	}
+	for _, p := range pairs {
+		sort.SliceStable(p.Markets, func(i, j int) bool { return p.Markets[i].PriceQuote < p.Markets[j].PriceQuote })
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].VolumeUsd24Hr() > pairs[j].VolumeUsd24Hr() })
+	return pairs
+}
+
+// Pair returns the markets of baseId/quoteId, false when none are listed.
+func (d MarketsData) Pair(baseId, quoteId string) (PairMarkets, bool) {
+	filtered := MarketsData{Timestamp: d.Timestamp}
+	for _, m := range d.Data {
+		if m.BaseId == baseId && m.QuoteId == quoteId {
+			filtered.Data = append(filtered.Data, m)
+		}
+	}
+	pairs := filtered.Pairs()
+	if len(pairs) == 0 {
+		return PairMarkets{}, false
+	}
+	return pairs[0], true
+}
+
+func (p PairMarkets) Cheapest() (Market, bool) {
+	if len(p.Markets) == 0 {
+		return Market{}, false
+	}
+	return p.Markets[0], true
+}
+
+func (p PairMarkets) Dearest() (Market, bool) {
+	if len(p.Markets) == 0 {
+		return Market{}, false
+	}
+	return p.Markets[len(p.Markets)-1], true
+}
+
+// Spread compares the cheapest and most expensive exchange, false with
+// fewer than two markets.
+func (p PairMarkets) Spread() (Spread, bool) {
+	if len(p.Markets) < 2 {
+		return Spread{}, false
+	}
+	low, high := p.Markets[0], p.Markets[len(p.Markets)-1]
+	s := Spread{Low: low, High: high, Quote: high.PriceQuote - low.PriceQuote}
+	s.Percent = s.Quote / low.PriceQuote * 100
+	return s, true
+}
+
+// Vwap is the average PriceQuote weighted by VolumeUsd24Hr, the plain
+// average when no market reports volume.
+func (p PairMarkets) Vwap() float64 {
+	return p.weighted(func(m Market) float64 { return m.PriceQuote })
+}
+
+// VwapUsd is Vwap over PriceUsd.
+func (p PairMarkets) VwapUsd() float64 {
+	return p.weighted(func(m Market) float64 { return m.PriceUsd })
+}
+
+func (p PairMarkets) weighted(price func(Market) float64) float64 {
+	var sum, volume float64
+	for _, m := range p.Markets {
+		sum += price(m) * m.VolumeUsd24Hr
+		volume += m.VolumeUsd24Hr
+	}
+	if volume > 0 {
+		return sum / volume
+	}
+	if len(p.Markets) == 0 {
+		return 0
+	}
+	sum = 0
+	for _, m := range p.Markets {
+		sum += price(m)
+	}
+	return sum / float64(len(p.Markets))
+}
+
+func (p PairMarkets) VolumeUsd24Hr() float64 {
+	var total float64
+	for _, m := range p.Markets {
+		total += m.VolumeUsd24Hr
+	}
+	return total
+}
@@ -0,0 +1,68 @@
+package coincap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketsData_Pairs(t *testing.T) {
+	var data MarketsData
+	require.NoError(t, unmarshalModel("markets", &data))
+	pairs := data.Pairs()
+	require.Len(t, pairs, 3)
+	require.Equal(t, Pair{BaseId: "solana", QuoteId: "tether"}, pairs[0].Pair)
+	require.Equal(t, "USDT", pairs[0].QuoteSymbol)
+	require.Equal(t, Pair{BaseId: "solana", QuoteId: "bitcoin"}, pairs[1].Pair)
+
+	var exchanges []string
+	for _, m := range pairs[0].Markets {
+		exchanges = append(exchanges, m.ExchangeId)
+	}
+	require.Equal(t, []string{"hotbit", "bilaxy", "bitmax", "binance"}, exchanges)
+
+	cheapest, ok := pairs[0].Cheapest()
+	require.True(t, ok)
+	require.Equal(t, "hotbit", cheapest.ExchangeId)
+	dearest, _ := pairs[0].Dearest()
+	require.Equal(t, "binance", dearest.ExchangeId)
+
+	spread, ok := pairs[0].Spread()
+	require.True(t, ok)
+	require.Equal(t, cheapest, spread.Low)
+	require.InDelta(t, 0.048963, spread.Quote, 1e-9)
+	require.InDelta(t, 0.161259, spread.Percent, 1e-6)
+	require.InDelta(t, 30.411233, pairs[0].Vwap(), 1e-6)
+	require.InDelta(t, 30.457680, pairs[0].VwapUsd(), 1e-6)
+
+	_, ok = pairs[2].Spread()
+	require.False(t, ok)
+	require.Equal(t, pairs[2].Markets[0].PriceQuote, pairs[2].Vwap())
+}
+
+func TestMarketsData_Pair(t *testing.T) {
+	var data MarketsData
+	require.NoError(t, unmarshalModel("markets", &data))
+	p, ok := data.Pair("solana", "bitcoin")
+	require.True(t, ok)
+	require.Len(t, p.Markets, 2)
+	_, ok = data.Pair("solana", "ethereum")
+	require.False(t, ok)
+
+	noVolume := PairMarkets{Markets: []Market{{PriceQuote: 2}, {PriceQuote: 4}}}
+	require.Equal(t, 3.0, noVolume.Vwap())
+	require.Zero(t, PairMarkets{}.Vwap())
+}
+
+func TestMarketsData_Fresh(t *testing.T) {
+	var data MarketsData
+	require.NoError(t, unmarshalModel("markets", &data))
+	fresh := data.Fresh(time.Minute * 5)
+	require.Len(t, fresh.Data, 6)
+	for _, m := range fresh.Data {
+		require.NotEqual(t, "bilaxy", m.ExchangeId)
+	}
+	require.Len(t, data.Fresh(time.Hour).Data, 7)
+	require.Empty(t, MarketsData{Data: data.Data}.Fresh(time.Hour).Data)
+}
@@ -0,0 +1,100 @@
+package coincap
+
+import "sort"
+
+// CirculatingRatio is Supply/MaxSupply, false when the asset has no max supply.
+func (a Asset) CirculatingRatio() (float64, bool) {
+	if a.MaxSupply == nil || *a.MaxSupply == 0 {
+		return 0, false
+	}
+	return a.Supply / *a.MaxSupply, true
+}
+
+// FullyDilutedValuation is PriceUsd*MaxSupply, false when the asset has no max supply.
+func (a Asset) FullyDilutedValuation() (float64, bool) {
+	if a.MaxSupply == nil || *a.MaxSupply == 0 {
+		return 0, false
+	}
+	return a.PriceUsd * *a.MaxSupply, true
+}
+
+// Turnover is the 24 hour volume to market cap ratio.
+func (a Asset) Turnover() float64 {
+	if a.MarketCapUsd == 0 {
+		return 0
+	}
+	return a.VolumeUsd24Hr / a.MarketCapUsd
+}
+
+// Dominance is the percentage of totalMarketCapUsd held by the asset.
+func (a Asset) Dominance(totalMarketCapUsd float64) float64 {
+	if totalMarketCapUsd == 0 {
+		return 0
+	}
+	return a.MarketCapUsd / totalMarketCapUsd * 100
+}
+
+func (d AssetsData) TotalMarketCapUsd() float64 {
+	var total float64
+	for _, a := range d.Data {
+		total += a.MarketCapUsd
+	}
+	return total
+}
+
+func (d AssetsData) TotalVolumeUsd24Hr() float64 {
+	var total float64
+	for _, a := range d.Data {
+		total += a.VolumeUsd24Hr
+	}
+	return total
+}
+
+// Dominance returns the market cap percentage of every asset keyed by id.
+func (d AssetsData) Dominance() map[string]float64 {
+	total := d.TotalMarketCapUsd()
+	m := make(map[string]float64, len(d.Data))
+	for _, a := range d.Data {
+		m[a.Id] = a.Dominance(total)
+	}
+	return m
+}
+
+// TopDominance is the market cap percentage held by the n largest assets.
+func (d AssetsData) TopDominance(n int) float64 {
+	total := d.TotalMarketCapUsd()
+	if total == 0 {
+		return 0
+	}
+	caps := make([]float64, len(d.Data))
+	for i, a := range d.Data {
+		caps[i] = a.MarketCapUsd
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(caps)))
+	if n > len(caps) {
+		n = len(caps)
+	}
+	if n < 0 {
+		n = 0
+	}
+	var top float64
+	for _, c := range caps[:n] {
+		top += c
+	}
+	return top / total * 100
+}
+
+// Herfindahl is the Herfindahl-Hirschman concentration of market caps,
+// the sum of squared market shares in the range (0, 1].
+func (d AssetsData) Herfindahl() float64 {
+	total := d.TotalMarketCapUsd()
+	if total == 0 {
+		return 0
+	}
+	var hhi float64
+	for _, a := range d.Data {
+		s := a.MarketCapUsd / total
+		hhi += s * s
+	}
+	return hhi
+}
@@ -0,0 +1,50 @@
+package coincap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsset_Metrics(t *testing.T) {
+	var data AssetsData
+	require.NoError(t, unmarshalModel("assets", &data))
+	btc := data.Data[0]
+	ratio, ok := btc.CirculatingRatio()
+	require.True(t, ok)
+	require.InDelta(t, 18767006.0/21000000.0, ratio, 1e-12)
+	fdv, ok := btc.FullyDilutedValuation()
+	require.True(t, ok)
+	require.InDelta(t, 38417.0478200847774256*21e6, fdv, 1e-3)
+	require.InDelta(t, 23924880952.7548243291986963/720972966941.8179384548997536, btc.Turnover(), 1e-12)
+
+	var dot AssetData
+	require.NoError(t, unmarshalModel("asset_id", &dot))
+	_, ok = dot.Asset.CirculatingRatio()
+	require.False(t, ok)
+	_, ok = dot.Asset.FullyDilutedValuation()
+	require.False(t, ok)
+
+	zero := 0.0
+	dot.Asset.MaxSupply = &zero
+	_, ok = dot.Asset.CirculatingRatio()
+	require.False(t, ok)
+	_, ok = dot.Asset.FullyDilutedValuation()
+	require.False(t, ok)
+}
+
+func TestAssetsData_Metrics(t *testing.T) {
+	data := AssetsData{Data: []Asset{
+		{Id: "a", MarketCapUsd: 50, VolumeUsd24Hr: 5},
+		{Id: "b", MarketCapUsd: 30, VolumeUsd24Hr: 3},
+		{Id: "c", MarketCapUsd: 20, VolumeUsd24Hr: 2},
+	}}
+	require.Equal(t, 100.0, data.TotalMarketCapUsd())
+	require.Equal(t, 10.0, data.TotalVolumeUsd24Hr())
+	require.Equal(t, map[string]float64{"a": 50, "b": 30, "c": 20}, data.Dominance())
+	require.Equal(t, 80.0, data.TopDominance(2))
+	require.Equal(t, 100.0, data.TopDominance(10))
+	require.Zero(t, data.TopDominance(-1))
+	require.InDelta(t, 0.25+0.09+0.04, data.Herfindahl(), 1e-12)
+	require.Zero(t, AssetsData{}.Herfindahl())
+}
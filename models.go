@@ -56,7 +56,7 @@ type AssetMarketsData struct {
 }
 
 type Rate struct {
-	Id             string  `json:"Id"`
+	Id             string  `json:"id"`
 	Symbol         string  `json:"symbol"`
 	CurrencySymbol *string `json:"currencySymbol"`
 	Type           string  `json:"type"`
@@ -122,3 +122,14 @@ type CandlesData struct {
 	Data      []Candle `json:"data,omitempty"`
 	Timestamp int64    `json:"timestamp"`
 }
+
+type Trade struct {
+	Exchange  string  `json:"exchange"`
+	Base      string  `json:"base"`
+	Quote     string  `json:"quote"`
+	Direction string  `json:"direction"`
+	Price     float64 `json:"price"`
+	Volume    float64 `json:"volume"`
+	Timestamp int64   `json:"timestamp"`
+	PriceUsd  float64 `json:"priceUsd"`
+}
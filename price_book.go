@@ -0,0 +1,172 @@
+package coincap
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Quote struct {
+	Id       string
+	PriceUsd float64
+	Updated  time.Time
+}
+
+func (q Quote) Age(now time.Time) time.Duration { return now.Sub(q.Updated) }
+
+// PriceBook keeps the latest price of every asset, fed by a PriceStream and
+// optionally seeded with GetAssets.
+type PriceBook struct {
+	staleAfter time.Duration
+	mu         sync.RWMutex
+	quotes     map[string]Quote
+	stale      map[string]bool
+	signals    map[string]chan struct{}
+}
+
+// NewPriceBook marks quotes not updated within staleAfter as stale, never when zero.
+func NewPriceBook(staleAfter time.Duration) *PriceBook {
+	return &PriceBook{
+		staleAfter: staleAfter,
+		quotes:     map[string]Quote{},
+		stale:      map[string]bool{},
+		signals:    map[string]chan struct{}{},
+	}
+}
+
+// Seed loads the given assets, or the top MaxLimit when no id is given, with the
+// response timestamp as their update time. Fresher quotes are kept.
+func (b *PriceBook) Seed(api Api, ids ...string) error {
+	params := GetAssetsParams{Ids: ids, LimitOffsetParams: LimitOffsetParams{Limit: MaxLimit}}
+	data, err := api.GetAssets(params)
+	if err != nil {
+		return err
+	}
+	updated := time.UnixMilli(data.Timestamp)
+	for _, a := range data.Data {
+		b.Set(Quote{Id: a.Id, PriceUsd: a.PriceUsd, Updated: updated})
+	}
+	return nil
+}
+
+// Update applies a stream update, see Set.
+func (b *PriceBook) Update(u PriceUpdate) {
+	b.Set(Quote{Id: u.Id, PriceUsd: u.PriceUsd, Updated: u.Received})
+}
+
+// Set stores q unless the book already holds a more recent quote.
+func (b *PriceBook) Set(q Quote) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if old, ok := b.quotes[q.Id]; ok && old.Updated.After(q.Updated) {
+		return
+	}
+	b.quotes[q.Id] = q
+	if b.stale[q.Id] && !b.expired(q, time.Now()) {
+		delete(b.stale, q.Id)
+		delete(b.signals, q.Id)
+	}
+}
+
+func (b *PriceBook) Get(id string) (Quote, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	q, ok := b.quotes[id]
+	return q, ok
+}
+
+// Age is the time since the quote of id was updated.
+func (b *PriceBook) Age(id string) (time.Duration, bool) {
+	q, ok := b.Get(id)
+	if !ok {
+		return 0, false
+	}
+	return q.Age(time.Now()), true
+}
+
+func (b *PriceBook) Snapshot() map[string]Quote {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	m := make(map[string]Quote, len(b.quotes))
+	for id, q := range b.quotes {
+		m[id] = q
+	}
+	return m
+}
+
+func (b *PriceBook) IsStale(id string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.stale[id]
+}
+
+// StaleQuotes lists the quotes currently marked stale, ordered by id.
+func (b *PriceBook) StaleQuotes() []Quote {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	quotes := make([]Quote, 0, len(b.stale))
+	for id := range b.stale {
+		quotes = append(quotes, b.quotes[id])
+	}
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].Id < quotes[j].Id })
+	return quotes
+}
+
+// Stale returns a channel closed once the quote of id goes stale. A fresh
+// update re-arms the signal, readers should call Stale again afterwards.
+func (b *PriceBook) Stale(id string) <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch, ok := b.signals[id]
+	if !ok {
+		ch = make(chan struct{})
+		if b.stale[id] {
+			close(ch)
+		}
+		b.signals[id] = ch
+	}
+	return ch
+}
+
+// Run applies updates, e.g. PriceStream.Updates(), and checks staleness until
+// updates is closed or ctx is done.
+func (b *PriceBook) Run(ctx context.Context, updates <-chan PriceUpdate) error {
+	var tick <-chan time.Time
+	if b.staleAfter > 0 {
+		ticker := time.NewTicker(b.staleAfter / 4)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case u, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			b.Update(u)
+		case now := <-tick:
+			b.expire(now)
+		}
+	}
+}
+
+func (b *PriceBook) expire(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, q := range b.quotes {
+		if b.stale[id] || !b.expired(q, now) {
+			continue
+		}
+		b.stale[id] = true
+		if ch, ok := b.signals[id]; ok {
+			close(ch)
+		}
+	}
+}
+
+func (b *PriceBook) expired(q Quote, now time.Time) bool {
+	return b.staleAfter > 0 && q.Age(now) > b.staleAfter
+}
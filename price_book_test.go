@@ -0,0 +1,74 @@
+package coincap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceBook_Seed(t *testing.T) {
+	var assets AssetsData
+	require.NoError(t, unmarshalModel("assets", &assets))
+	b := NewPriceBook(0)
+	require.NoError(t, b.Seed(&assetsPager{assets: assets.Data}))
+	require.Len(t, b.Snapshot(), 5)
+	q, ok := b.Get("bitcoin")
+	require.True(t, ok)
+	require.Equal(t, 38417.0478200847774256, q.PriceUsd)
+	require.Equal(t, time.UnixMilli(1), q.Updated)
+
+	now := time.Now()
+	b.Update(PriceUpdate{Id: "bitcoin", PriceUsd: 40000, Received: now})
+	b.Update(PriceUpdate{Id: "bitcoin", PriceUsd: 30000, Received: now.Add(-time.Minute), Backfill: true})
+	q, _ = b.Get("bitcoin")
+	require.Equal(t, 40000.0, q.PriceUsd)
+	age, ok := b.Age("bitcoin")
+	require.True(t, ok)
+	require.True(t, age >= 0 && age < time.Minute)
+	_, ok = b.Age("dogecoin")
+	require.False(t, ok)
+}
+
+func TestPriceBook_Stale(t *testing.T) {
+	b := NewPriceBook(time.Minute)
+	now := time.Now()
+	b.Update(PriceUpdate{Id: "bitcoin", PriceUsd: 1, Received: now.Add(-time.Minute * 2)})
+	b.Update(PriceUpdate{Id: "ethereum", PriceUsd: 1, Received: now})
+	signal := b.Stale("bitcoin")
+	b.expire(now)
+	<-signal
+	require.True(t, b.IsStale("bitcoin"))
+	require.False(t, b.IsStale("ethereum"))
+	require.Equal(t, []string{"bitcoin"}, quoteIds(b.StaleQuotes()))
+
+	b.Update(PriceUpdate{Id: "bitcoin", PriceUsd: 2, Received: now})
+	require.False(t, b.IsStale("bitcoin"))
+	select {
+	case <-b.Stale("bitcoin"):
+		t.Fatal("fresh quote signalled stale")
+	default:
+	}
+}
+
+func TestPriceBook_Run(t *testing.T) {
+	b := NewPriceBook(time.Millisecond * 20)
+	updates := make(chan PriceUpdate)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() { errs <- b.Run(ctx, updates) }()
+	updates <- PriceUpdate{Id: "bitcoin", PriceUsd: 1, Received: time.Now()}
+	<-b.Stale("bitcoin")
+	close(updates)
+	require.NoError(t, <-errs)
+}
+
+func quoteIds(quotes []Quote) []string {
+	ids := make([]string, len(quotes))
+	for i, q := range quotes {
+		ids[i] = q.Id
+	}
+	return ids
+}
@@ -0,0 +1,147 @@
+package coincap
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AllAssets subscribes a PriceStream to every asset.
+const AllAssets = "ALL"
+
+type PriceUpdate struct {
+	Id       string
+	PriceUsd float64
+	Received time.Time // for backfilled updates, the history Date
+	Backfill bool      // recovered from GetAssetHistory after an outage
+}
+
+// PriceStream consumes the prices socket, e.g. wss://ws.coincap.io/prices?assets=bitcoin,ethereum
+type PriceStream struct {
+	config  streamConfig
+	mu      sync.Mutex
+	assets  []string
+	restart chan struct{}
+	updates chan PriceUpdate
+}
+
+func NewPriceStream(assets []string, options ...StreamOption) *PriceStream {
+	cfg := newStreamConfig(options)
+	return &PriceStream{
+		config:  cfg,
+		assets:  append([]string(nil), assets...),
+		restart: make(chan struct{}, 1),
+		updates: make(chan PriceUpdate, cfg.buffer),
+	}
+}
+
+// Updates is closed once Run returns.
+func (s *PriceStream) Updates() <-chan PriceUpdate { return s.updates }
+
+func (s *PriceStream) Assets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.assets...)
+}
+
+// Subscribe replaces the subscribed assets. CoinCap has no subscription
+// messages, so the running connection is replaced by a new one.
+func (s *PriceStream) Subscribe(assets ...string) {
+	s.mu.Lock()
+	s.assets = append([]string(nil), assets...)
+	s.mu.Unlock()
+	select {
+	case s.restart <- struct{}{}:
+	default:
+	}
+}
+
+// Run streams until ctx is done or the connection fails, see WithReconnect.
+// It may be called once.
+func (s *PriceStream) Run(ctx context.Context) error {
+	defer close(s.updates)
+	var backfill backfillFunc
+	if s.config.backfill != nil {
+		backfill = s.backfill
+	}
+	return s.config.run(ctx, s.path, s.restart, backfill, func(msg []byte, received time.Time) error {
+		return s.emit(ctx, msg, received)
+	})
+}
+
+func (s *PriceStream) path() (string, error) {
+	assets := s.Assets()
+	if len(assets) == 0 {
+		return "", MissingParameterError
+	}
+	return "/prices?assets=" + strings.Join(assets, ","), nil
+}
+
+func (s *PriceStream) emit(ctx context.Context, msg []byte, received time.Time) error {
+	updates, err := decodePrices(msg, received)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, updates)
+}
+
+// backfill sends the M1 history of every subscribed asset for the minutes in [from, to).
+func (s *PriceStream) backfill(ctx context.Context, from, to time.Time) error {
+	start := from.Truncate(time.Minute)
+	if start.Add(M1.Value()).After(to) {
+		return nil
+	}
+	for _, id := range s.Assets() {
+		if id == AllAssets {
+			continue
+		}
+		history, err := s.config.backfill.GetAssetHistory(GetAssetHistoryParams{
+			Id:            id,
+			HistoryParams: HistoryParams{Interval: M1, Start: start, End: to},
+		})
+		if err != nil {
+			return err
+		}
+		updates := make([]PriceUpdate, len(history.Data))
+		for i, h := range history.Data {
+			updates[i] = PriceUpdate{Id: id, PriceUsd: h.PriceUsd, Received: h.Date, Backfill: true}
+		}
+		if err := s.send(ctx, updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PriceStream) send(ctx context.Context, updates []PriceUpdate) error {
+	for _, u := range updates {
+		select {
+		case s.updates <- u:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// decodePrices parses {"bitcoin":"6929.82","ethereum":"404.97"}, ordered by id.
+func decodePrices(msg []byte, received time.Time) ([]PriceUpdate, error) {
+	var prices map[string]string
+	if err := json.Unmarshal(msg, &prices); err != nil {
+		return nil, err
+	}
+	updates := make([]PriceUpdate, 0, len(prices))
+	for id, v := range prices {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, PriceUpdate{Id: id, PriceUsd: price, Received: received})
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Id < updates[j].Id })
+	return updates, nil
+}
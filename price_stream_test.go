@@ -0,0 +1,80 @@
+package coincap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// wsServer upgrades every request and hands the connection and its request uri to serve.
+func wsServer(t *testing.T, serve func(conn *websocket.Conn, uri string)) (*httptest.Server, StreamOption) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(conn, r.URL.RequestURI())
+	}))
+	t.Cleanup(srv.Close)
+	return srv, WithStreamUrl("ws" + strings.TrimPrefix(srv.URL, "http"))
+}
+
+func drain(conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func TestPriceStream(t *testing.T) {
+	uris := make(chan string, 2)
+	_, option := wsServer(t, func(conn *websocket.Conn, uri string) {
+		uris <- uri
+		if strings.Contains(uri, "bitcoin") {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"ethereum":"404.97","bitcoin":"6929.82"}`))
+		} else {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"solana":"30.5"}`))
+		}
+		drain(conn)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	s := NewPriceStream([]string{"bitcoin", "ethereum"}, option)
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	require.Equal(t, "/prices?assets=bitcoin,ethereum", <-uris)
+	u := <-s.Updates()
+	require.Equal(t, "bitcoin", u.Id)
+	require.Equal(t, 6929.82, u.PriceUsd)
+	require.False(t, u.Received.IsZero())
+	require.Equal(t, "ethereum", (<-s.Updates()).Id)
+
+	s.Subscribe("solana")
+	require.Equal(t, "/prices?assets=solana", <-uris)
+	require.Equal(t, PriceUpdate{Id: "solana", PriceUsd: 30.5}, withoutReceived(<-s.Updates()))
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+	_, ok := <-s.Updates()
+	require.False(t, ok)
+}
+
+func TestPriceStream_NoAssets(t *testing.T) {
+	require.ErrorIs(t, NewPriceStream(nil).Run(context.Background()), MissingParameterError)
+}
+
+func withoutReceived(u PriceUpdate) PriceUpdate {
+	u.Received = time.Time{}
+	return u
+}
@@ -15,13 +15,32 @@ type queryParams interface {
 	toQuery() (map[string]string, error)
 }
 
+// MaxLimit is the largest page CoinCap serves, for both Limit and Ids.
+const MaxLimit = 2000
+
 type LimitOffsetParams struct {
-	Limit  int // optional, max limit of 2000
+	Limit  int // optional, max limit of MaxLimit
 	Offset int // optional
 }
 
+// Paginate calls fetch with pages of MaxLimit rows until one comes back
+// short, and returns the rows of every page.
+func Paginate[T any](fetch func(page LimitOffsetParams) ([]T, error)) ([]T, error) {
+	var rows []T
+	for offset := 0; ; offset += MaxLimit {
+		page, err := fetch(LimitOffsetParams{Limit: MaxLimit, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, page...)
+		if len(page) < MaxLimit {
+			return rows, nil
+		}
+	}
+}
+
 func (r LimitOffsetParams) toQuery() (map[string]string, error) {
-	if r.Limit > 2000 {
+	if r.Limit > MaxLimit {
 		return nil, InvalidParameterError
 	}
 	q := make(map[string]string, 2)
@@ -92,7 +111,7 @@ type GetAssetsParams struct {
 
 func (r GetAssetsParams) toQuery() (map[string]string, error) {
 	q := make(map[string]string, 4)
-	if r.Limit > 2000 || len(r.Ids) > 2000 {
+	if r.Limit > MaxLimit || len(r.Ids) > MaxLimit {
 		return nil, InvalidParameterError
 	}
 	if len(r.Search) > 0 {
@@ -1,6 +1,8 @@
 package coincap
 
 import (
+	"errors"
+
 	"github.com/stretchr/testify/assert"
 	"strconv"
 	"testing"
@@ -17,6 +19,24 @@ func TestLimitOffsetParams(t *testing.T) {
 	assert.Equal(t, map[string]string{"limit": "10", "offset": "10"}, q)
 }
 
+func TestPaginate(t *testing.T) {
+	var pages []LimitOffsetParams
+	rows, err := Paginate(func(p LimitOffsetParams) ([]int, error) {
+		pages = append(pages, p)
+		if n := MaxLimit*2 + 1 - p.Offset; n < MaxLimit {
+			return make([]int, n), nil
+		}
+		return make([]int, MaxLimit), nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rows, MaxLimit*2+1)
+	assert.Equal(t, []LimitOffsetParams{{MaxLimit, 0}, {MaxLimit, MaxLimit}, {MaxLimit, MaxLimit * 2}}, pages)
+
+	broken := errors.New("broken")
+	_, err = Paginate(func(p LimitOffsetParams) ([]int, error) { return nil, broken })
+	assert.ErrorIs(t, err, broken)
+}
+
 func TestHistoryParams(t *testing.T) {
 	p := HistoryParams{Interval: M30, Start: t1, End: t2}
 	q, err := p.toQuery()
@@ -34,3 +54,12 @@ func TestGetAssetsParams(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, map[string]string{"ids": "polkadot,solana"}, q)
 }
+
+func TestHistoryParams_M1(t *testing.T) {
+	p := HistoryParams{Interval: M1, Start: t1, End: t1.Add(time.Hour)}
+	q, err := p.toQuery()
+	assert.NoError(t, err)
+	assert.Equal(t, "m1", q["interval"])
+	_, err = HistoryParams{}.toQuery()
+	assert.ErrorIs(t, err, MissingParameterError)
+}
@@ -0,0 +1,156 @@
+package coincap
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var NoReferenceMarketsError = errors.New("no markets left to price the asset")
+
+// madScale and meanAbsScale make the median and mean absolute deviations
+// comparable to a standard deviation for normally distributed prices.
+const (
+	madScale     = 1.4826
+	meanAbsScale = 1.2533
+)
+
+type ReferencePriceParams struct {
+	Id               string  // required, asset Id
+	MaxDeviation     float64 // optional, scaled deviations from the median before a market is an outlier, defaults to 3
+	MinVolumeUsd24Hr float64 // optional, exclude markets trading less
+}
+
+type ExclusionReason string
+
+const (
+	ExcludedNoPrice   ExclusionReason = "no price"
+	ExcludedLowVolume ExclusionReason = "low volume"
+	ExcludedOutlier   ExclusionReason = "outlier"
+)
+
+// ReferenceMarket is one line of the audit trail.
+type ReferenceMarket struct {
+	AssetMarket
+	Deviation float64         // distance from the median in scaled MADs
+	Weight    float64         // share of the included volume, 0 when excluded
+	Reason    ExclusionReason // empty when included
+}
+
+type ReferencePrice struct {
+	Id            string
+	PriceUsd      float64 // volume weighted over Included
+	MedianUsd     float64 // median over the markets that passed the price and volume checks
+	Mad           float64 // median absolute deviation around MedianUsd
+	Scale         float64 // unit of Deviation, the scaled MAD or mean absolute deviation when the MAD is zero
+	VolumeUsd24Hr float64 // volume of Included
+	Included      []ReferenceMarket
+	Excluded      []ReferenceMarket
+	Timestamp     int64 // of the markets response
+}
+
+// GetReferencePrice prices an asset independently of CoinCap's aggregate
+// from every market it trades on, see NewReferencePrice.
+func GetReferencePrice(api Api, params ReferencePriceParams) (ReferencePrice, error) {
+	if len(params.Id) == 0 {
+		return ReferencePrice{}, MissingParameterError
+	}
+	var markets AssetMarketsData
+	var err error
+	markets.Data, err = Paginate(func(p LimitOffsetParams) ([]AssetMarket, error) {
+		page, err := api.GetAssetMarkets(GetAssetMarketsParams{Id: params.Id, LimitOffsetParams: p})
+		markets.Timestamp = page.Timestamp
+		return page.Data, err
+	})
+	if err != nil {
+		return ReferencePrice{}, err
+	}
+	return NewReferencePrice(markets, params)
+}
+
+// NewReferencePrice drops markets without a price or below MinVolumeUsd24Hr,
+// then markets whose price deviates more than MaxDeviation scaled MADs from
+// the median, and weights the rest by volume. When more than half of the
+// markets agree on the exact price the MAD is zero, deviations are then
+// measured in mean absolute deviations instead.
+func NewReferencePrice(markets AssetMarketsData, params ReferencePriceParams) (ReferencePrice, error) {
+	maxDeviation := params.MaxDeviation
+	if maxDeviation == 0 {
+		maxDeviation = 3
+	}
+	r := ReferencePrice{Id: params.Id, Timestamp: markets.Timestamp}
+	var candidates []ReferenceMarket
+	for _, m := range markets.Data {
+		rm := ReferenceMarket{AssetMarket: m}
+		switch {
+		case m.PriceUsd <= 0:
+			rm.Reason = ExcludedNoPrice
+		case m.VolumeUsd24Hr < params.MinVolumeUsd24Hr:
+			rm.Reason = ExcludedLowVolume
+		default:
+			candidates = append(candidates, rm)
+			continue
+		}
+		r.Excluded = append(r.Excluded, rm)
+	}
+	if len(candidates) == 0 {
+		return r, NoReferenceMarketsError
+	}
+
+	prices := make([]float64, len(candidates))
+	for i, m := range candidates {
+		prices[i] = m.PriceUsd
+	}
+	r.MedianUsd = median(prices)
+	for i, m := range candidates {
+		prices[i] = math.Abs(m.PriceUsd - r.MedianUsd)
+	}
+	r.Mad = median(prices)
+	r.Scale = r.Mad * madScale
+	if r.Scale == 0 {
+		r.Scale = mean(prices) * meanAbsScale
+	}
+
+	for _, m := range candidates {
+		if r.Scale > 0 {
+			m.Deviation = math.Abs(m.PriceUsd-r.MedianUsd) / r.Scale
+		}
+		if m.Deviation > maxDeviation {
+			m.Reason = ExcludedOutlier
+			r.Excluded = append(r.Excluded, m)
+			continue
+		}
+		r.Included = append(r.Included, m)
+		r.VolumeUsd24Hr += m.VolumeUsd24Hr
+	}
+
+	var sum float64
+	for i, m := range r.Included {
+		if r.VolumeUsd24Hr > 0 {
+			r.Included[i].Weight = m.VolumeUsd24Hr / r.VolumeUsd24Hr
+		} else {
+			r.Included[i].Weight = 1 / float64(len(r.Included))
+		}
+		sum += m.PriceUsd * r.Included[i].Weight
+	}
+	r.PriceUsd = sum
+	return r, nil
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
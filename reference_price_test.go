@@ -0,0 +1,103 @@
+package coincap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReferencePrice(t *testing.T) {
+	var data AssetMarketsData
+	require.NoError(t, unmarshalModel("asset_markets", &data))
+	api := &FakeApi{GetAssetMarketsFunc: func(GetAssetMarketsParams) (AssetMarketsData, error) { return data, nil }}
+
+	r, err := GetReferencePrice(api, ReferencePriceParams{Id: "polkadot"})
+	require.NoError(t, err)
+	require.Equal(t, "polkadot", r.Id)
+	require.Equal(t, data.Timestamp, r.Timestamp)
+	require.Len(t, r.Included, 7)
+	require.Empty(t, r.Excluded)
+	require.InDelta(t, 14.796487, r.PriceUsd, 1e-6)
+	require.InDelta(t, 14.781951, r.MedianUsd, 1e-6)
+	require.InDelta(t, 0.026965, r.Mad, 1e-6)
+	api.AssertCalled(t, "GetAssetMarkets", GetAssetMarketsParams{Id: "polkadot", LimitOffsetParams: LimitOffsetParams{Limit: MaxLimit}})
+
+	r, err = GetReferencePrice(api, ReferencePriceParams{Id: "polkadot", MaxDeviation: 1.5})
+	require.NoError(t, err)
+	require.Len(t, r.Included, 6)
+	require.Len(t, r.Excluded, 1)
+	require.Equal(t, "Gate", r.Excluded[0].ExchangeId)
+	require.Equal(t, ExcludedOutlier, r.Excluded[0].Reason)
+	require.InDelta(t, 1.772468, r.Excluded[0].Deviation, 1e-6)
+	require.Zero(t, r.Excluded[0].Weight)
+	require.InDelta(t, 14.795609, r.PriceUsd, 1e-6)
+	var weights float64
+	for _, m := range r.Included {
+		require.Empty(t, m.Reason)
+		weights += m.Weight
+	}
+	require.InDelta(t, 1, weights, 1e-12)
+
+	r, err = GetReferencePrice(api, ReferencePriceParams{Id: "polkadot", MinVolumeUsd24Hr: 5e6})
+	require.NoError(t, err)
+	require.Len(t, r.Excluded, 1)
+	require.Equal(t, "Bitfinex", r.Excluded[0].ExchangeId)
+	require.Equal(t, ExcludedLowVolume, r.Excluded[0].Reason)
+	require.InDelta(t, 14.796432, r.PriceUsd, 1e-6)
+
+	_, err = GetReferencePrice(api, ReferencePriceParams{})
+	require.ErrorIs(t, err, MissingParameterError)
+	api.FailNext("GetAssetMarkets", NotFoundError)
+	_, err = GetReferencePrice(api, ReferencePriceParams{Id: "polkadot"})
+	require.ErrorIs(t, err, NotFoundError)
+}
+
+func TestGetReferencePrice_Pages(t *testing.T) {
+	markets := make([]AssetMarket, MaxLimit+3)
+	for i := range markets {
+		markets[i] = AssetMarket{ExchangeId: fmt.Sprint("exchange", i), PriceUsd: 10, VolumeUsd24Hr: 1}
+	}
+	api := &FakeApi{GetAssetMarketsFunc: func(p GetAssetMarketsParams) (AssetMarketsData, error) {
+		end := p.Offset + p.Limit
+		if end > len(markets) {
+			end = len(markets)
+		}
+		return AssetMarketsData{Data: markets[p.Offset:end], Timestamp: int64(p.Offset)}, nil
+	}}
+	r, err := GetReferencePrice(api, ReferencePriceParams{Id: "bitcoin"})
+	require.NoError(t, err)
+	require.Len(t, r.Included, len(markets))
+	require.Equal(t, int64(MaxLimit), r.Timestamp)
+	api.AssertCallCount(t, "GetAssetMarkets", 2)
+	api.AssertCalled(t, "GetAssetMarkets", GetAssetMarketsParams{Id: "bitcoin", LimitOffsetParams: LimitOffsetParams{Limit: MaxLimit, Offset: MaxLimit}})
+}
+
+func TestNewReferencePrice(t *testing.T) {
+	markets := AssetMarketsData{Data: []AssetMarket{
+		{ExchangeId: "a", PriceUsd: 10},
+		{ExchangeId: "b", PriceUsd: 10},
+		{ExchangeId: "c", PriceUsd: 10},
+		{ExchangeId: "d", PriceUsd: 13},
+		{ExchangeId: "e"},
+	}}
+	r, err := NewReferencePrice(markets, ReferencePriceParams{Id: "x"})
+	require.NoError(t, err)
+	require.Zero(t, r.Mad)
+	require.InDelta(t, 0.75*meanAbsScale, r.Scale, 1e-12)
+	require.Len(t, r.Included, 3)
+	require.Equal(t, 10.0, r.PriceUsd)
+	require.Len(t, r.Excluded, 2)
+	require.Equal(t, ReferenceMarket{AssetMarket: markets.Data[4], Reason: ExcludedNoPrice}, r.Excluded[0])
+	require.Equal(t, "d", r.Excluded[1].ExchangeId)
+	require.Equal(t, ExcludedOutlier, r.Excluded[1].Reason)
+
+	same := AssetMarketsData{Data: markets.Data[:3]}
+	r, err = NewReferencePrice(same, ReferencePriceParams{Id: "x"})
+	require.NoError(t, err)
+	require.Zero(t, r.Scale)
+	require.Len(t, r.Included, 3)
+
+	_, err = NewReferencePrice(AssetMarketsData{Data: markets.Data[4:]}, ReferencePriceParams{Id: "x"})
+	require.ErrorIs(t, err, NoReferenceMarketsError)
+}
@@ -0,0 +1,192 @@
+// Package report builds venue liquidity reports from CoinCap exchanges and
+// markets, as structured data and as plain text tables.
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/esenmx/coincap-go/format"
+)
+
+type Liquidity struct {
+	Exchanges []ExchangeProfile     // by VolumeUsd, largest first
+	Assets    []AssetConcentration  // by VolumeUsd24Hr, largest first
+	TopPairs  []coincap.PairMarkets // by VolumeUsd24Hr across venues, largest first
+}
+
+type ExchangeProfile struct {
+	coincap.Exchange
+	Markets             int              // markets listed by GetMarkets
+	MarketVolumeUsd24Hr float64          // sum of the listed market volumes
+	TopMarketShare      float64          // share of the largest market in MarketVolumeUsd24Hr
+	Herfindahl          float64          // concentration of volume across markets, (0, 1]
+	TopMarkets          []coincap.Market // largest markets by volume
+}
+
+// AssetConcentration shows where an asset trades, counting markets where it
+// is the base asset.
+type AssetConcentration struct {
+	AssetId       string
+	Symbol        string
+	VolumeUsd24Hr float64
+	Venues        []VenueShare // largest first
+	Herfindahl    float64      // concentration of volume across venues, (0, 1]
+}
+
+type VenueShare struct {
+	ExchangeId    string
+	VolumeUsd24Hr float64
+	Share         float64 // fraction of the asset volume
+}
+
+// BuildLiquidity fetches every exchange and pages through every market.
+func BuildLiquidity(api coincap.Api, top int) (Liquidity, error) {
+	exchanges, err := api.GetExchanges()
+	if err != nil {
+		return Liquidity{}, err
+	}
+	markets, err := coincap.Paginate(func(p coincap.LimitOffsetParams) ([]coincap.Market, error) {
+		page, err := api.GetMarkets(coincap.GetMarketsParams{LimitOffsetParams: p})
+		return page.Data, err
+	})
+	if err != nil {
+		return Liquidity{}, err
+	}
+	return NewLiquidity(exchanges.Data, markets, top), nil
+}
+
+// NewLiquidity keeps the top markets per exchange and the top pairs overall,
+// everything when top is not positive.
+func NewLiquidity(exchanges []coincap.Exchange, markets []coincap.Market, top int) Liquidity {
+	if top <= 0 {
+		top = len(markets)
+	}
+	var l Liquidity
+	byExchange := make(map[string][]coincap.Market)
+	byAsset := make(map[string][]coincap.Market)
+	for _, m := range markets {
+		byExchange[m.ExchangeId] = append(byExchange[m.ExchangeId], m)
+		byAsset[m.BaseId] = append(byAsset[m.BaseId], m)
+	}
+
+	for _, e := range exchanges {
+		p := ExchangeProfile{Exchange: e}
+		ms := byExchange[e.ExchangeId]
+		sortByVolume(ms)
+		p.Markets = len(ms)
+		volumes := make([]float64, len(ms))
+		for i, m := range ms {
+			volumes[i] = m.VolumeUsd24Hr
+			p.MarketVolumeUsd24Hr += m.VolumeUsd24Hr
+		}
+		if p.MarketVolumeUsd24Hr > 0 {
+			p.TopMarketShare = ms[0].VolumeUsd24Hr / p.MarketVolumeUsd24Hr
+		}
+		p.Herfindahl = herfindahl(volumes)
+		p.TopMarkets = ms[:min(top, len(ms))]
+		l.Exchanges = append(l.Exchanges, p)
+	}
+	sort.SliceStable(l.Exchanges, func(i, j int) bool { return l.Exchanges[i].VolumeUsd > l.Exchanges[j].VolumeUsd })
+
+	for id, ms := range byAsset {
+		a := AssetConcentration{AssetId: id, Symbol: ms[0].BaseSymbol}
+		venues := make(map[string]float64)
+		for _, m := range ms {
+			venues[m.ExchangeId] += m.VolumeUsd24Hr
+			a.VolumeUsd24Hr += m.VolumeUsd24Hr
+		}
+		volumes := make([]float64, 0, len(venues))
+		for exchangeId, volume := range venues {
+			v := VenueShare{ExchangeId: exchangeId, VolumeUsd24Hr: volume}
+			if a.VolumeUsd24Hr > 0 {
+				v.Share = volume / a.VolumeUsd24Hr
+			}
+			a.Venues = append(a.Venues, v)
+			volumes = append(volumes, volume)
+		}
+		sort.Slice(a.Venues, func(i, j int) bool {
+			if a.Venues[i].VolumeUsd24Hr != a.Venues[j].VolumeUsd24Hr {
+				return a.Venues[i].VolumeUsd24Hr > a.Venues[j].VolumeUsd24Hr
+			}
+			return a.Venues[i].ExchangeId < a.Venues[j].ExchangeId
+		})
+		a.Herfindahl = herfindahl(volumes)
+		l.Assets = append(l.Assets, a)
+	}
+	sort.Slice(l.Assets, func(i, j int) bool {
+		if l.Assets[i].VolumeUsd24Hr != l.Assets[j].VolumeUsd24Hr {
+			return l.Assets[i].VolumeUsd24Hr > l.Assets[j].VolumeUsd24Hr
+		}
+		return l.Assets[i].AssetId < l.Assets[j].AssetId
+	})
+
+	pairs := coincap.MarketsData{Data: markets}.Pairs()
+	l.TopPairs = pairs[:min(top, len(pairs))]
+	return l
+}
+
+func (l Liquidity) WriteExchanges(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Exchange\tVolume\tTotal\tPairs\tMarkets\tTop market\tHHI\t")
+	for _, p := range l.Exchanges {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t\n", p.Name, format.USD.Compact(p.VolumeUsd), share(p.PercentTotalVolume/100),
+			p.TradingPairs, p.Markets, share(p.TopMarketShare), format.Number(p.Herfindahl, 3))
+	}
+	return tw.Flush()
+}
+
+func (l Liquidity) WriteAssets(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Asset\tVolume\tVenues\tTop venue\tShare\tHHI\t")
+	for _, a := range l.Assets {
+		top := a.Venues[0]
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t\n", a.Symbol, format.USD.Compact(a.VolumeUsd24Hr), len(a.Venues),
+			top.ExchangeId, share(top.Share), format.Number(a.Herfindahl, 3))
+	}
+	return tw.Flush()
+}
+
+func (l Liquidity) WritePairs(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Pair\tVolume\tVenues\tVWAP\t")
+	for _, p := range l.TopPairs {
+		fmt.Fprintf(tw, "%s/%s\t%s\t%d\t%s\t\n", p.BaseSymbol, p.QuoteSymbol, format.USD.Compact(p.VolumeUsd24Hr()),
+			len(p.Markets), format.USD.Price(p.VwapUsd()))
+	}
+	return tw.Flush()
+}
+
+func sortByVolume(markets []coincap.Market) {
+	sort.SliceStable(markets, func(i, j int) bool { return markets[i].VolumeUsd24Hr > markets[j].VolumeUsd24Hr })
+}
+
+// herfindahl is the sum of squared shares of volumes, 0 without volume.
+func herfindahl(volumes []float64) float64 {
+	var total float64
+	for _, v := range volumes {
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+	var hhi float64
+	for _, v := range volumes {
+		hhi += (v / total) * (v / total)
+	}
+	return hhi
+}
+
+func share(fraction float64) string {
+	return format.Number(fraction*100, 2) + "%"
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
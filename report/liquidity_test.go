@@ -0,0 +1,87 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureApi(t *testing.T) *coincap.FakeApi {
+	var exchanges coincap.ExchangesData
+	var markets coincap.MarketsData
+	for name, ptr := range map[string]interface{}{"exchanges": &exchanges, "markets": &markets} {
+		b, err := os.ReadFile("../mock/" + name + ".json")
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, ptr))
+	}
+	return &coincap.FakeApi{
+		GetExchangesFunc: func() (coincap.ExchangesData, error) { return exchanges, nil },
+		GetMarketsFunc:   func(coincap.GetMarketsParams) (coincap.MarketsData, error) { return markets, nil },
+	}
+}
+
+func TestBuildLiquidity(t *testing.T) {
+	api := fixtureApi(t)
+	l, err := BuildLiquidity(api, 2)
+	require.NoError(t, err)
+	api.AssertCallCount(t, "GetMarkets", 1)
+
+	require.Len(t, l.Exchanges, 5)
+	binance := l.Exchanges[0]
+	require.Equal(t, "binance", binance.ExchangeId)
+	require.Equal(t, 3, binance.Markets)
+	require.Len(t, binance.TopMarkets, 2)
+	require.Equal(t, "tether", binance.TopMarkets[0].QuoteId)
+	require.InDelta(t, 151001680.30+17114711.84+1469906.09, binance.MarketVolumeUsd24Hr, 0.1)
+	require.InDelta(t, 151001680.30/binance.MarketVolumeUsd24Hr, binance.TopMarketShare, 1e-9)
+	require.Greater(t, binance.Herfindahl, binance.TopMarketShare*binance.TopMarketShare)
+	require.Zero(t, l.Exchanges[1].Markets)
+	require.Zero(t, l.Exchanges[1].Herfindahl)
+
+	require.Len(t, l.Assets, 1)
+	solana := l.Assets[0]
+	require.Equal(t, "SOL", solana.Symbol)
+	require.Len(t, solana.Venues, 4)
+	require.Equal(t, "binance", solana.Venues[0].ExchangeId)
+	var shares float64
+	for _, v := range solana.Venues {
+		shares += v.Share
+	}
+	require.InDelta(t, 1, shares, 1e-12)
+
+	require.Len(t, l.TopPairs, 2)
+	require.Equal(t, "tether", l.TopPairs[0].QuoteId)
+
+	api.FailNext("GetMarkets", coincap.RateLimitError)
+	_, err = BuildLiquidity(api, 2)
+	require.ErrorIs(t, err, coincap.RateLimitError)
+}
+
+func TestLiquidity_Tables(t *testing.T) {
+	l, err := BuildLiquidity(fixtureApi(t), 0)
+	require.NoError(t, err)
+	require.Len(t, l.TopPairs, 3)
+
+	var buf bytes.Buffer
+	require.NoError(t, l.WriteExchanges(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 6)
+	require.Equal(t, strings.Fields("Exchange Volume Total Pairs Markets Top market HHI"), strings.Fields(lines[0]))
+	require.Equal(t, strings.Fields("Binance $22.89B 34.05% 674 3 89.04% 0.803"), strings.Fields(lines[1]))
+
+	buf.Reset()
+	require.NoError(t, l.WriteAssets(&buf))
+	require.Contains(t, buf.String(), "SOL")
+	require.Contains(t, buf.String(), "binance")
+
+	buf.Reset()
+	require.NoError(t, l.WritePairs(&buf))
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	require.True(t, strings.HasPrefix(strings.TrimSpace(lines[1]), "SOL/USDT"))
+}
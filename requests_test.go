@@ -1,26 +1,39 @@
-package coincap
+package coincap_test
 
 import (
-	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/esenmx/coincap-go/coincaptest"
+	"github.com/stretchr/testify/require"
 )
 
-var client = NewClient()
+// testClient serves the mock/*.json fixtures, applying query parameters the
+// way CoinCap does.
+func testClient(t *testing.T) *coincap.Client {
+	data, err := coincaptest.Fixtures("mock")
+	require.NoError(t, err)
+	data.Assets = append(data.Assets, coincap.Asset{Id: "solana", Symbol: "SOL", Rank: 7})
+	s := coincaptest.NewServer(data)
+	t.Cleanup(s.Close)
+	return s.Client()
+}
 
 func TestClient_GetAssets(t *testing.T) {
-	assets, err := client.GetAssets(GetAssetsParams{LimitOffsetParams: LimitOffsetParams{Limit: 10, Offset: 10}})
+	client := testClient(t)
+	assets, err := client.GetAssets(coincap.GetAssetsParams{LimitOffsetParams: coincap.LimitOffsetParams{Limit: 3, Offset: 2}})
 	require.NoError(t, err)
-	require.Equal(t, 10, len(assets.Data))
-	ranks := make([]int, 10)
+	require.Equal(t, 3, len(assets.Data))
+	ranks := make([]int, 3)
 	for i, v := range assets.Data {
 		ranks[i] = v.Rank
 	}
-	require.ElementsMatch(t, ranks, []int{11, 12, 13, 14, 15, 16, 17, 18, 19, 20})
+	require.ElementsMatch(t, ranks, []int{3, 4, 5})
 }
 
 func TestClient_GetAsset(t *testing.T) {
-	asset, err := client.GetAsset("polkadot")
+	asset, err := testClient(t).GetAsset("polkadot")
 	require.NoError(t, err)
 	require.Equal(t, "DOT", asset.Asset.Symbol)
 	require.Equal(t, "polkadot", asset.Asset.Id)
@@ -28,37 +41,37 @@ func TestClient_GetAsset(t *testing.T) {
 }
 
 func TestClient_GetAssetHistory(t *testing.T) {
-	interval := time.Hour * 24 * 7
-	start := time.Now().Truncate(time.Hour * 24).UTC().Add(-interval)
-	end := start.Add(interval)
+	client := testClient(t)
+	start := time.Date(2021, 7, 26, 11, 0, 0, 0, time.UTC)
+	end := start.Add(coincap.M30.Value() * 4)
 	t.Run("EndExclusive", func(t *testing.T) {
-		history, err := client.GetAssetHistory(GetAssetHistoryParams{
+		history, err := client.GetAssetHistory(coincap.GetAssetHistoryParams{
 			Id:            "polkadot",
-			HistoryParams: HistoryParams{Interval: H1, Start: start, End: end},
+			HistoryParams: coincap.HistoryParams{Interval: coincap.M30, Start: start, End: end},
 		})
 		require.NoError(t, err)
 		l := len(history.Data)
-		require.Equal(t, 24*7, l)
+		require.Equal(t, 4, l)
 		require.Equal(t, start, history.Data[0].Date)
-		require.Equal(t, end.Add(-H1.Value()), history.Data[l-1].Date)
+		require.Equal(t, end.Add(-coincap.M30.Value()), history.Data[l-1].Date)
 	})
 
 	t.Run("EndInclusive", func(t *testing.T) {
-		params := GetAssetHistoryParams{
+		params := coincap.GetAssetHistoryParams{
 			Id:            "polkadot",
-			HistoryParams: HistoryParams{Interval: H1, Start: start, End: end.Add(time.Second)},
+			HistoryParams: coincap.HistoryParams{Interval: coincap.M30, Start: start, End: end.Add(time.Second)},
 		}
 		history, err := client.GetAssetHistory(params)
 		require.NoError(t, err, params)
 		l := len(history.Data)
-		require.Equal(t, 24*7+1, l)
+		require.Equal(t, 5, l)
 		require.Equal(t, start, history.Data[0].Date)
 		require.Equal(t, end, history.Data[l-1].Date)
 	})
 }
 
 func TestClient_GetAssetMarkets(t *testing.T) {
-	ams, err := client.GetAssetMarkets(GetAssetMarketsParams{Id: "solana"})
+	ams, err := testClient(t).GetAssetMarkets(coincap.GetAssetMarketsParams{Id: "solana"})
 	require.NoError(t, err)
 	require.NotEmpty(t, ams.Data)
 	for _, v := range ams.Data {
@@ -67,65 +80,71 @@ func TestClient_GetAssetMarkets(t *testing.T) {
 }
 
 func TestClient_GetRates(t *testing.T) {
-	rates, err := client.GetRates()
+	rates, err := testClient(t).GetRates()
 	require.NoError(t, err)
 	require.NotEmpty(t, rates.Data)
 	symbols := make([]string, len(rates.Data))
 	for i, v := range rates.Data {
 		symbols[i] = v.Symbol
 	}
-	require.Subset(t, symbols, []string{"USD", "GBP", "EUR", "USDT", "AUD", "TRY", "BTC", "ETH", "CAD"})
+	require.Subset(t, symbols, []string{"USD", "USDC", "USDT", "AUD", "TRY"})
 }
 
 func TestClient_GetRate(t *testing.T) {
-	rate, err := client.GetRate("australian-dollar")
+	rate, err := testClient(t).GetRate("usd-coin")
 	require.NoError(t, err)
-	require.Equal(t, "AUD", rate.Data.Symbol)
+	require.Equal(t, "USDC", rate.Data.Symbol)
 }
 
 func TestClient_GetExchanges(t *testing.T) {
-	exchanges, err := client.GetExchanges()
+	exchanges, err := testClient(t).GetExchanges()
 	require.NoError(t, err)
 	require.NotEmpty(t, exchanges)
 	require.Equal(t, "binance", exchanges.Data[0].ExchangeId)
 }
 
 func TestClient_GetExchange(t *testing.T) {
-	exchange, err := client.GetExchange("kraken")
+	exchange, err := testClient(t).GetExchange("kraken")
 	require.NoError(t, err)
 	require.Equal(t, "kraken", exchange.Data.ExchangeId)
 	require.Equal(t, "Kraken", exchange.Data.Name)
 }
 
 func TestClient_GetMarkets(t *testing.T) {
+	client := testClient(t)
 	t.Run("WithAsset", func(t *testing.T) {
-		markets, err := client.GetMarkets(GetMarketsParams{ExchangeId: "kraken", AssetSymbol: "ADA"})
+		markets, err := client.GetMarkets(coincap.GetMarketsParams{AssetId: "solana"})
 		require.NoError(t, err)
 		require.NotEmpty(t, markets.Data)
 		for _, v := range markets.Data {
-			require.Equal(t, "cardano", v.BaseId)
+			require.True(t, v.BaseId == "solana" || v.QuoteId == "solana", v)
 		}
 	})
 
 	t.Run("WithBase/Quote", func(t *testing.T) {
-		linkUsdc, err := client.GetMarkets(GetMarketsParams{ExchangeId: "binance", BaseSymbol: "link", QuoteId: "usd-coin"})
+		solUsdt, err := client.GetMarkets(coincap.GetMarketsParams{BaseSymbol: "SOL", QuoteId: "tether"})
 		require.NoError(t, err)
-		require.Equal(t, "LINK", linkUsdc.Data[0].BaseSymbol)
-		require.Equal(t, "USDC", linkUsdc.Data[0].QuoteSymbol)
+		require.NotEmpty(t, solUsdt.Data)
+		for _, v := range solUsdt.Data {
+			require.Equal(t, "SOL", v.BaseSymbol)
+			require.Equal(t, "USDT", v.QuoteSymbol)
+		}
 	})
 }
 
 func TestClient_GetCandles(t *testing.T) {
-	candles, err := client.GetCandles(GetCandlesParams{
+	start := time.UnixMilli(1627281000000).UTC()
+	candles, err := testClient(t).GetCandles(coincap.GetCandlesParams{
 		Exchange: "binance",
-		BaseId:   "bitcoin",
+		BaseId:   "polkadot",
 		QuoteId:  "tether",
-		HistoryParams: HistoryParams{
-			Interval: D1,
-			Start:    t1,
-			End:      t1.Add(time.Hour * 30 * 24),
+		HistoryParams: coincap.HistoryParams{
+			Interval: coincap.M30,
+			Start:    start,
+			End:      start.Add(coincap.M30.Value() * 4),
 		},
 	})
 	require.NoError(t, err)
-	require.Equal(t, 30, len(candles.Data))
+	require.Equal(t, 4, len(candles.Data))
+	require.Empty(t, coincap.HistoryParams{Interval: coincap.M30}.CandleGaps(candles.Data))
 }
@@ -0,0 +1,131 @@
+package coincap
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	dayDuration  = time.Hour * 24
+	weekDuration = dayDuration * 7
+)
+
+// Resampler aggregates candles and history into coarser buckets. Periods of
+// whole weeks start on Monday, whole days at midnight and divisors of a day
+// at wall clock multiples from midnight, all in Location, so buckets follow
+// daylight saving changes. Other periods are aligned to the UNIX epoch.
+type Resampler struct {
+	Period   time.Duration  // required, e.g. H1.Multiple(4) or time.Hour*24*7
+	Location *time.Location // optional, UTC when nil
+}
+
+// NewResampler fails with InvalidParameterError unless period is positive.
+func NewResampler(period time.Duration, loc *time.Location) (Resampler, error) {
+	if period <= 0 {
+		return Resampler{}, InvalidParameterError
+	}
+	return Resampler{Period: period, Location: loc}, nil
+}
+
+// Multiple returns n times the interval duration.
+func (i Interval) Multiple(n int) time.Duration { return i.Value() * time.Duration(n) }
+
+// Bucket returns the start of the bucket containing t, t itself when Period
+// is not positive.
+func (r Resampler) Bucket(t time.Time) time.Time {
+	loc := r.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	if r.Period <= 0 {
+		return t
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch {
+	case r.Period%weekDuration == 0:
+		// 1970-01-05 is the first Monday after the epoch
+		days := civilDays(midnight) - 4
+		n := int64(r.Period / dayDuration)
+		return midnight.AddDate(0, 0, -int(floorMod(days, n)))
+	case r.Period%dayDuration == 0:
+		n := int64(r.Period / dayDuration)
+		return midnight.AddDate(0, 0, -int(floorMod(civilDays(midnight), n)))
+	case dayDuration%r.Period == 0:
+		wall := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, int(wall-wall%r.Period), loc)
+	default:
+		ns := t.UnixNano()
+		return time.Unix(0, ns-floorMod(ns, int64(r.Period))).In(loc)
+	}
+}
+
+// Candles merges candles into OHLCV candles of the resampler period.
+func (r Resampler) Candles(candles []Candle) []Candle {
+	sorted := append([]Candle(nil), candles...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Period < sorted[j].Period })
+	var out []Candle
+	for _, c := range sorted {
+		period := r.Bucket(time.UnixMilli(c.Period)).UnixMilli()
+		if n := len(out); n > 0 && out[n-1].Period == period {
+			last := &out[n-1]
+			if c.High > last.High {
+				last.High = c.High
+			}
+			if c.Low < last.Low {
+				last.Low = c.Low
+			}
+			last.Close = c.Close
+			last.Volume += c.Volume
+			continue
+		}
+		c.Period = period
+		out = append(out, c)
+	}
+	return out
+}
+
+// HistoryCandles builds OHLC candles from history prices, without volume.
+func (r Resampler) HistoryCandles(history []AssetHistory) []Candle {
+	candles := make([]Candle, len(history))
+	for i, h := range history {
+		candles[i] = Candle{Open: h.PriceUsd, High: h.PriceUsd, Low: h.PriceUsd, Close: h.PriceUsd, Period: h.Time}
+	}
+	return r.Candles(candles)
+}
+
+// History averages prices per bucket like CoinCap history intervals, keeping
+// the last circulating supply. Time and Date are the bucket start.
+func (r Resampler) History(history []AssetHistory) []AssetHistory {
+	sorted := append([]AssetHistory(nil), history...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	var out []AssetHistory
+	var count int
+	for _, h := range sorted {
+		bucket := r.Bucket(time.UnixMilli(h.Time))
+		if n := len(out); n > 0 && out[n-1].Time == bucket.UnixMilli() {
+			last := &out[n-1]
+			count++
+			last.PriceUsd += (h.PriceUsd - last.PriceUsd) / float64(count)
+			last.CirculatingSupply = h.CirculatingSupply
+			continue
+		}
+		count = 1
+		out = append(out, AssetHistory{PriceUsd: h.PriceUsd, Time: bucket.UnixMilli(), CirculatingSupply: h.CirculatingSupply, Date: bucket.UTC()})
+	}
+	return out
+}
+
+// civilDays counts calendar days since 1970-01-01 of the date of t in its location.
+func civilDays(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / int64(dayDuration/time.Second)
+}
+
+func floorMod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
@@ -0,0 +1,98 @@
+package coincap
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func resampler(t *testing.T, period time.Duration, loc *time.Location) Resampler {
+	r, err := NewResampler(period, loc)
+	require.NoError(t, err)
+	return r
+}
+
+func TestResampler_Bucket(t *testing.T) {
+	utc := resampler(t, H1.Multiple(4), nil)
+	ts := time.Date(2021, 7, 26, 11, 30, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2021, 7, 26, 8, 0, 0, 0, time.UTC), utc.Bucket(ts).UTC())
+
+	weekly := resampler(t, D1.Multiple(7), nil)
+	require.Equal(t, time.Date(2021, 7, 26, 0, 0, 0, 0, time.UTC), weekly.Bucket(ts).UTC()) // a Monday
+	require.Equal(t, time.Date(2021, 7, 19, 0, 0, 0, 0, time.UTC), weekly.Bucket(ts.AddDate(0, 0, -1)).UTC())
+
+	istanbul := time.FixedZone("TRT", 3*60*60)
+	local := resampler(t, D1.Value(), istanbul)
+	require.Equal(t, time.Date(2021, 7, 26, 0, 0, 0, 0, istanbul), local.Bucket(time.Date(2021, 7, 25, 21, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2021, 7, 25, 0, 0, 0, 0, istanbul), local.Bucket(time.Date(2021, 7, 25, 20, 59, 0, 0, time.UTC)))
+	localWeekly := resampler(t, weekly.Period, istanbul)
+	require.Equal(t, time.Weekday(time.Monday), localWeekly.Bucket(ts).Weekday())
+
+	odd := resampler(t, time.Minute*7, nil)
+	require.Equal(t, time.Date(2021, 7, 26, 11, 27, 0, 0, time.UTC), odd.Bucket(ts))
+	require.Zero(t, odd.Bucket(ts).Unix()%(7*60))
+
+	_, err := NewResampler(0, nil)
+	require.ErrorIs(t, err, InvalidParameterError)
+	require.Equal(t, ts, Resampler{}.Bucket(ts))
+}
+
+func TestResampler_BucketDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// clocks jump from 02:00 to 03:00 on 2021-03-28
+	at := func(hour, min int) time.Time { return time.Date(2021, 3, 28, hour, min, 0, 0, berlin) }
+	sixHours := resampler(t, H6.Value(), berlin)
+	require.Equal(t, at(0, 0), sixHours.Bucket(at(4, 30)))
+	require.Equal(t, at(6, 0), sixHours.Bucket(at(8, 30)))
+	fourHours := resampler(t, H1.Multiple(4), berlin)
+	require.Equal(t, at(4, 0), fourHours.Bucket(at(4, 30)))
+	require.Equal(t, at(4, 0), fourHours.Bucket(at(5, 0)))
+	require.Equal(t, at(8, 0), fourHours.Bucket(at(8, 30)))
+	require.Equal(t, at(3, 0), resampler(t, H1.Value(), berlin).Bucket(at(3, 59)))
+}
+
+func TestResampler_Candles(t *testing.T) {
+	var data CandlesData
+	require.NoError(t, unmarshalModel("candles", &data))
+	hourly := resampler(t, H1.Value(), nil).Candles(data.Data)
+	var volume float64
+	for _, c := range data.Data {
+		volume += c.Volume
+	}
+	var resampled float64
+	for _, c := range hourly {
+		resampled += c.Volume
+		require.Zero(t, c.Period%H1.Value().Milliseconds())
+		require.True(t, c.Low <= c.Open && c.Open <= c.High && c.Low <= c.Close && c.Close <= c.High)
+	}
+	require.InDelta(t, volume, resampled, 1e-6)
+
+	candles := []Candle{
+		{Open: 3, High: 4, Low: 2, Close: 3.5, Volume: 2, Period: t1.Add(time.Hour).UnixMilli()},
+		{Open: 1, High: 3, Low: 1, Close: 3, Volume: 1, Period: t1.UnixMilli()},
+		{Open: 5, High: 5, Low: 5, Close: 5, Volume: 1, Period: t1.Add(time.Hour * 4).UnixMilli()},
+	}
+	require.Equal(t, []Candle{
+		{Open: 1, High: 4, Low: 1, Close: 3.5, Volume: 3, Period: t1.UnixMilli()},
+		{Open: 5, High: 5, Low: 5, Close: 5, Volume: 1, Period: t1.Add(time.Hour * 4).UnixMilli()},
+	}, resampler(t, H2.Multiple(2), nil).Candles(candles))
+}
+
+func TestResampler_History(t *testing.T) {
+	var data AssetHistoriesData
+	require.NoError(t, unmarshalModel("asset_history", &data))
+	r := resampler(t, H1.Value(), nil)
+	history := r.History(data.Data)
+	require.Len(t, history, 3)
+	require.Equal(t, data.Data[0].Date, history[0].Date)
+	require.InDelta(t, (data.Data[0].PriceUsd+data.Data[1].PriceUsd)/2, history[0].PriceUsd, 1e-9)
+	require.Equal(t, data.Data[1].CirculatingSupply, history[0].CirculatingSupply)
+
+	candles := r.HistoryCandles(data.Data)
+	require.Len(t, candles, 3)
+	require.Equal(t, data.Data[0].PriceUsd, candles[0].Open)
+	require.Equal(t, data.Data[1].PriceUsd, candles[0].Close)
+}
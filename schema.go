@@ -0,0 +1,170 @@
+package coincap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var SchemaMismatchError = errors.New("schema mismatch")
+
+type SchemaMode int
+
+const (
+	SchemaIgnore SchemaMode = iota // decode leniently, the default
+	SchemaWarn                     // report issues to the callback, decode anyway
+	SchemaStrict                   // report issues to the callback and fail with *SchemaError
+)
+
+type SchemaIssueKind int
+
+const (
+	UnknownField SchemaIssueKind = iota // present in the response, not in the model
+	MissingField                        // present in the model, not in the response
+)
+
+func (k SchemaIssueKind) String() string {
+	switch k {
+	case UnknownField:
+		return "unknown field"
+	case MissingField:
+		return "missing field"
+	default:
+		return ""
+	}
+}
+
+type SchemaIssue struct {
+	Kind SchemaIssueKind
+	Path string // e.g. "data[].maxSupply"
+}
+
+func (i SchemaIssue) String() string { return fmt.Sprintf("%s %s", i.Kind, i.Path) }
+
+type SchemaCallback func(url string, issues []SchemaIssue)
+
+type SchemaError struct {
+	Url    string
+	Issues []SchemaIssue
+}
+
+func (e *SchemaError) Error() string {
+	s := make([]string, len(e.Issues))
+	for i, v := range e.Issues {
+		s[i] = v.String()
+	}
+	return fmt.Sprintf("%s: %s: %s", SchemaMismatchError, e.Url, strings.Join(s, ", "))
+}
+
+func (e *SchemaError) Is(target error) bool { return target == SchemaMismatchError }
+
+// WithSchemaCheck compares every response against the model it is decoded into.
+func WithSchemaCheck(mode SchemaMode, callback SchemaCallback) Option {
+	return func(c *Client) {
+		c.schemaMode = mode
+		c.schemaCallback = callback
+	}
+}
+
+func (c *Client) checkSchema(url string, bs []byte, ptr interface{}) error {
+	var raw interface{}
+	if err := json.Unmarshal(bs, &raw); err != nil {
+		return err
+	}
+	issues := SchemaIssues(reflect.TypeOf(ptr), raw)
+	if len(issues) == 0 {
+		return nil
+	}
+	if c.schemaCallback != nil {
+		c.schemaCallback(url, issues)
+	}
+	if c.schemaMode == SchemaStrict {
+		return &SchemaError{Url: url, Issues: issues}
+	}
+	return nil
+}
+
+// SchemaIssues lists the differences between a decoded JSON value and the
+// json tags of t. Field names are compared case-sensitively.
+func SchemaIssues(t reflect.Type, raw interface{}) []SchemaIssue {
+	found := map[SchemaIssue]struct{}{}
+	walkSchema(t, raw, "", found)
+	issues := make([]SchemaIssue, 0, len(found))
+	for issue := range found {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Kind < issues[j].Kind
+	})
+	return issues
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func walkSchema(t reflect.Type, raw interface{}, path string, found map[SchemaIssue]struct{}) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if raw == nil || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if items, ok := raw.([]interface{}); ok {
+			for _, item := range items {
+				walkSchema(t.Elem(), item, path+"[]", found)
+			}
+		}
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for name, field := range fields {
+			v, ok := obj[name]
+			if !ok {
+				found[SchemaIssue{Kind: MissingField, Path: joinPath(path, name)}] = struct{}{}
+				continue
+			}
+			walkSchema(field.Type, v, joinPath(path, name), found)
+		}
+		for name := range obj {
+			if _, ok := fields[name]; !ok {
+				found[SchemaIssue{Kind: UnknownField, Path: joinPath(path, name)}] = struct{}{}
+			}
+		}
+	}
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+func joinPath(path, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,72 @@
+package coincap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func bodyClient(body []byte, options ...Option) *Client {
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(body)), Request: r}, nil
+	})
+	return NewClient(append([]Option{WithHttpClient(&http.Client{Transport: rt})}, options...)...)
+}
+
+func TestSchemaIssues_Mocks(t *testing.T) {
+	models := map[string]interface{}{
+		"assets":        &AssetsData{},
+		"asset_id":      &AssetData{},
+		"asset_history": &AssetHistoriesData{},
+		"asset_markets": &AssetMarketsData{},
+		"rates":         &RatesData{},
+		"rates_id":      &RateData{},
+		"exchanges":     &ExchangesData{},
+		"exchange":      &ExchangeData{},
+		"markets":       &MarketsData{},
+		"candles":       &CandlesData{},
+	}
+	for name, ptr := range models {
+		var raw interface{}
+		require.NoError(t, unmarshalModel(name, &raw))
+		require.Empty(t, SchemaIssues(reflect.TypeOf(ptr), raw), name)
+	}
+}
+
+func TestClient_SchemaCheck(t *testing.T) {
+	bs, err := os.ReadFile("mock/rates_id.json")
+	require.NoError(t, err)
+	drifted := bytes.Replace(bs, []byte(`"type"`), []byte(`"kind"`), 1)
+
+	var reported []SchemaIssue
+	callback := func(url string, issues []SchemaIssue) { reported = issues }
+	expected := []SchemaIssue{{Kind: UnknownField, Path: "data.kind"}, {Kind: MissingField, Path: "data.type"}}
+
+	var data RateData
+	require.NoError(t, bodyClient(drifted).Do(url, nil, &data))
+	require.Nil(t, reported)
+
+	require.NoError(t, bodyClient(drifted, WithSchemaCheck(SchemaWarn, callback)).Do(url, nil, &data))
+	require.Equal(t, expected, reported)
+	require.Equal(t, "USDC", data.Data.Symbol)
+
+	reported = nil
+	err = bodyClient(drifted, WithSchemaCheck(SchemaStrict, callback)).Do(url, nil, &data)
+	require.True(t, errors.Is(err, SchemaMismatchError))
+	var schemaErr *SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	require.Equal(t, expected, schemaErr.Issues)
+	require.Equal(t, expected, reported)
+
+	require.NoError(t, bodyClient(bs, WithSchemaCheck(SchemaStrict, nil)).Do(url, nil, &data))
+}
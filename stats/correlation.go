@@ -0,0 +1,221 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/esenmx/coincap-go"
+)
+
+// Matrix is a square matrix labelled on both axes.
+type Matrix struct {
+	Labels []string
+	Values [][]float64
+}
+
+// At returns the value for a pair of labels, NaN if either is unknown.
+func (m Matrix) At(a, b string) float64 {
+	i, j := m.index(a), m.index(b)
+	if i < 0 || j < 0 {
+		return math.NaN()
+	}
+	return m.Values[i][j]
+}
+
+func (m Matrix) index(label string) int {
+	for i, l := range m.Labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteCSV writes a header row of labels followed by one labelled row per
+// label. NaN values are written as empty cells.
+func (m Matrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{""}, m.Labels...)); err != nil {
+		return err
+	}
+	for i, row := range m.Values {
+		record := make([]string, len(row)+1)
+		record[0] = m.Labels[i]
+		for j, v := range row {
+			if !math.IsNaN(v) {
+				record[j+1] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type Correlation struct {
+	Matrix    Matrix             // correlation of log returns between ids
+	Benchmark string             // empty when no betas were requested
+	Betas     map[string]float64 // beta of each id against Benchmark
+	Times     []time.Time        // union of the timestamps of every series
+}
+
+// maxFetches bounds the concurrent history requests of Correlate, CoinCap
+// rate limits per client.
+const maxFetches = 4
+
+// Correlate fetches the history of ids, and of benchmark when set, with up to
+// maxFetches concurrent requests and correlates their log returns, see
+// CorrelateHistory.
+func Correlate(api coincap.Api, ids []string, benchmark string, params coincap.HistoryParams) (Correlation, error) {
+	fetch := append([]string(nil), ids...)
+	if benchmark != "" {
+		fetch = append(fetch, benchmark)
+	}
+	histories := make(map[string][]coincap.AssetHistory, len(fetch))
+	started := make(map[string]bool, len(fetch))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	slots := make(chan struct{}, maxFetches)
+	for _, id := range fetch {
+		if started[id] {
+			continue
+		}
+		started[id] = true
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			data, err := api.GetAssetHistory(coincap.GetAssetHistoryParams{Id: id, HistoryParams: params})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", id, err)
+				}
+				return
+			}
+			histories[id] = data.Data
+		}(id)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return Correlation{}, firstErr
+	}
+	return CorrelateHistory(histories, ids, benchmark), nil
+}
+
+// CorrelateHistory aligns the series of ids and benchmark on the union of
+// their timestamps. A missing point leaves NaN returns around it and every
+// pair is computed over the returns both series have, so one sparse asset
+// does not shrink the window of the others.
+func CorrelateHistory(histories map[string][]coincap.AssetHistory, ids []string, benchmark string) Correlation {
+	var times []int64
+	seen := make(map[int64]struct{})
+	for _, history := range histories {
+		for _, h := range history {
+			if _, ok := seen[h.Time]; !ok {
+				seen[h.Time] = struct{}{}
+				times = append(times, h.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	returns := func(id string) []float64 {
+		byTime := make(map[int64]float64, len(histories[id]))
+		for _, h := range histories[id] {
+			byTime[h.Time] = h.PriceUsd
+		}
+		prices := make([]float64, len(times))
+		for i, t := range times {
+			if p, ok := byTime[t]; ok {
+				prices[i] = p
+			} else {
+				prices[i] = math.NaN()
+			}
+		}
+		return Series{Prices: prices}.LogReturns()
+	}
+
+	c := Correlation{Matrix: Matrix{Labels: append([]string(nil), ids...)}, Benchmark: benchmark}
+	for _, t := range times {
+		c.Times = append(c.Times, time.UnixMilli(t).UTC())
+	}
+	r := make([][]float64, len(ids))
+	for i, id := range ids {
+		r[i] = returns(id)
+	}
+	c.Matrix.Values = make([][]float64, len(ids))
+	for i := range ids {
+		c.Matrix.Values[i] = make([]float64, len(ids))
+		for j := range ids {
+			if j < i {
+				c.Matrix.Values[i][j] = c.Matrix.Values[j][i]
+				continue
+			}
+			c.Matrix.Values[i][j] = correlation(r[i], r[j])
+		}
+	}
+	if benchmark != "" {
+		b := returns(benchmark)
+		c.Betas = make(map[string]float64, len(ids))
+		for i, id := range ids {
+			c.Betas[id] = beta(r[i], b)
+		}
+	}
+	return c
+}
+
+// pairs keeps the indexes where both x and y are set.
+func pairs(x, y []float64) (xs, ys []float64) {
+	for i := range x {
+		if i < len(y) && !math.IsNaN(x[i]) && !math.IsNaN(y[i]) {
+			xs, ys = append(xs, x[i]), append(ys, y[i])
+		}
+	}
+	return xs, ys
+}
+
+func correlation(x, y []float64) float64 {
+	xs, ys := pairs(x, y)
+	cov, varX, varY := covariance(xs, ys)
+	return cov / math.Sqrt(varX*varY)
+}
+
+func beta(x, benchmark []float64) float64 {
+	xs, bs := pairs(x, benchmark)
+	cov, _, varB := covariance(xs, bs)
+	return cov / varB
+}
+
+// covariance returns the sample covariance and variances, NaN below two points.
+func covariance(x, y []float64) (cov, varX, varY float64) {
+	n := len(x)
+	if n < 2 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx, my = mx/float64(n), my/float64(n)
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	d := float64(n - 1)
+	return cov / d, varX / d, varY / d
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+func historyOf(prices ...float64) []coincap.AssetHistory {
+	start := time.Date(2021, 7, 26, 0, 0, 0, 0, time.UTC)
+	var history []coincap.AssetHistory
+	for i, p := range prices {
+		if math.IsNaN(p) {
+			continue
+		}
+		t := start.Add(time.Hour * 24 * time.Duration(i))
+		history = append(history, coincap.AssetHistory{PriceUsd: p, Time: t.UnixMilli(), Date: t})
+	}
+	return history
+}
+
+var nan = math.NaN()
+
+// ethereum moves twice as much as bitcoin in log terms and misses a day,
+// tether moves against it.
+var histories = map[string][]coincap.AssetHistory{
+	"bitcoin":  historyOf(100, 110, 99, 121, 115),
+	"ethereum": historyOf(100*100, 110*110, nan, 121*121, 115*115),
+	"tether":   historyOf(1/100.0, 1/110.0, 1/99.0, 1/121.0, 1/115.0),
+}
+
+func TestCorrelateHistory(t *testing.T) {
+	c := CorrelateHistory(histories, []string{"ethereum", "tether"}, "bitcoin")
+	require.Equal(t, []string{"ethereum", "tether"}, c.Matrix.Labels)
+	require.Len(t, c.Times, 5)
+	require.InDelta(t, 1, c.Matrix.At("ethereum", "ethereum"), 1e-12)
+	require.InDelta(t, -1, c.Matrix.At("ethereum", "tether"), 1e-12)
+	require.Equal(t, c.Matrix.At("ethereum", "tether"), c.Matrix.At("tether", "ethereum"))
+	require.True(t, math.IsNaN(c.Matrix.At("ethereum", "bitcoin")))
+
+	require.Equal(t, "bitcoin", c.Benchmark)
+	require.InDelta(t, 2, c.Betas["ethereum"], 1e-12)
+	require.InDelta(t, -1, c.Betas["tether"], 1e-12)
+
+	require.Nil(t, CorrelateHistory(histories, []string{"bitcoin"}, "").Betas)
+}
+
+func TestCorrelate(t *testing.T) {
+	api := &coincap.FakeApi{GetAssetHistoryFunc: func(p coincap.GetAssetHistoryParams) (coincap.AssetHistoriesData, error) {
+		if _, ok := histories[p.Id]; !ok {
+			return coincap.AssetHistoriesData{}, coincap.NotFoundError
+		}
+		return coincap.AssetHistoriesData{Data: histories[p.Id]}, nil
+	}}
+	params := coincap.HistoryParams{Interval: coincap.D1}
+	c, err := Correlate(api, []string{"bitcoin", "ethereum", "tether"}, "bitcoin", params)
+	require.NoError(t, err)
+	require.InDelta(t, 1, c.Matrix.At("bitcoin", "ethereum"), 1e-12)
+	require.InDelta(t, 1, c.Betas["bitcoin"], 1e-12)
+	api.AssertCallCount(t, "GetAssetHistory", 3)
+
+	var running, peak int32
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprint("asset", i)
+	}
+	bounded := &coincap.FakeApi{GetAssetHistoryFunc: func(p coincap.GetAssetHistoryParams) (coincap.AssetHistoriesData, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 5)
+		return coincap.AssetHistoriesData{Data: histories["bitcoin"]}, nil
+	}}
+	_, err = Correlate(bounded, ids, "", params)
+	require.NoError(t, err)
+	bounded.AssertCallCount(t, "GetAssetHistory", len(ids))
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(maxFetches))
+
+	_, err = Correlate(api, []string{"bitcoin", "dogecoin"}, "", params)
+	require.True(t, errors.Is(err, coincap.NotFoundError))
+	require.Contains(t, err.Error(), "dogecoin")
+}
+
+func TestMatrix_WriteCSV(t *testing.T) {
+	m := Matrix{Labels: []string{"bitcoin", "ethereum"}, Values: [][]float64{{1, 0.5}, {0.5, nan}}}
+	var buf bytes.Buffer
+	require.NoError(t, m.WriteCSV(&buf))
+	require.Equal(t, ",bitcoin,ethereum\nbitcoin,1,0.5\nethereum,0.5,\n", buf.String())
+}
@@ -0,0 +1,203 @@
+// Package stats computes return and risk statistics over CoinCap price
+// series. Statistics are annualized from the series period assuming markets
+// trade around the clock, 365 days a year, and are per period when the
+// series has no Period. NaN prices, such as the markers
+// inserted by HistoryParams.FillHistory, produce NaN returns which are
+// skipped by every statistic.
+package stats
+
+import (
+	"math"
+	"time"
+
+	"github.com/esenmx/coincap-go"
+)
+
+const year = time.Hour * 24 * 365
+
+type Series struct {
+	Prices []float64
+	Period time.Duration // spacing of Prices, used for annualization
+}
+
+func FromHistory(history []coincap.AssetHistory, interval coincap.Interval) Series {
+	prices := make([]float64, len(history))
+	for i, h := range history {
+		prices[i] = h.PriceUsd
+	}
+	return Series{Prices: prices, Period: interval.Value()}
+}
+
+// FromCandles uses the close of each candle.
+func FromCandles(candles []coincap.Candle, interval coincap.Interval) Series {
+	prices := make([]float64, len(candles))
+	for i, c := range candles {
+		prices[i] = c.Close
+	}
+	return Series{Prices: prices, Period: interval.Value()}
+}
+
+// PeriodsPerYear is the annualization factor of s, zero without a Period.
+func (s Series) PeriodsPerYear() float64 {
+	if s.Period <= 0 {
+		return 0
+	}
+	return float64(year) / float64(s.Period)
+}
+
+// scale turns per period deviations into annual ones, 1 without a Period.
+func (s Series) scale() float64 {
+	if ppy := s.PeriodsPerYear(); ppy > 0 {
+		return math.Sqrt(ppy)
+	}
+	return 1
+}
+
+// SimpleReturns has one value less than Prices: p[i+1]/p[i] - 1.
+func (s Series) SimpleReturns() []float64 {
+	return s.returns(func(prev, cur float64) float64 { return cur/prev - 1 })
+}
+
+// LogReturns has one value less than Prices: ln(p[i+1]/p[i]).
+func (s Series) LogReturns() []float64 {
+	return s.returns(func(prev, cur float64) float64 { return math.Log(cur / prev) })
+}
+
+func (s Series) returns(f func(prev, cur float64) float64) []float64 {
+	if len(s.Prices) < 2 {
+		return nil
+	}
+	out := make([]float64, len(s.Prices)-1)
+	for i := range out {
+		out[i] = f(s.Prices[i], s.Prices[i+1])
+	}
+	return out
+}
+
+// Volatility is the annualized sample standard deviation of the log returns,
+// the per period one without a Period.
+func (s Series) Volatility() float64 {
+	_, std := meanStd(s.LogReturns())
+	return std * s.scale()
+}
+
+// Sharpe is the annualized Sharpe ratio of the simple returns, riskFree is
+// an annual rate. Without a Period the ratio is per period and riskFree is
+// ignored.
+func (s Series) Sharpe(riskFree float64) float64 {
+	excess := s.excessReturns(riskFree)
+	mean, std := meanStd(excess)
+	return mean / std * s.scale()
+}
+
+// Sortino is like Sharpe but only penalizes returns below the risk free rate.
+func (s Series) Sortino(riskFree float64) float64 {
+	excess := s.excessReturns(riskFree)
+	mean, _ := meanStd(excess)
+	var sum float64
+	var n int
+	for _, r := range excess {
+		if math.IsNaN(r) {
+			continue
+		}
+		n++
+		if r < 0 {
+			sum += r * r
+		}
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return mean / math.Sqrt(sum/float64(n)) * s.scale()
+}
+
+func (s Series) excessReturns(riskFree float64) []float64 {
+	returns := s.SimpleReturns()
+	if ppy := s.PeriodsPerYear(); ppy > 0 {
+		perPeriod := math.Pow(1+riskFree, 1/ppy) - 1
+		for i := range returns {
+			returns[i] -= perPeriod
+		}
+	}
+	return returns
+}
+
+type Drawdown struct {
+	Depth    float64 // fall from the peak as a fraction, 0.25 is -25%
+	Peak     int     // index in Prices
+	Trough   int     // index in Prices
+	Recovery int     // first index back at the peak price, -1 if not recovered
+}
+
+// MaxDrawdown is the largest peak to trough fall of the prices.
+func (s Series) MaxDrawdown() Drawdown {
+	max := Drawdown{Peak: -1, Trough: -1, Recovery: -1}
+	peak := -1
+	for i, p := range s.Prices {
+		if math.IsNaN(p) {
+			continue
+		}
+		if peak < 0 || p >= s.Prices[peak] {
+			peak = i
+			if max.Peak >= 0 && max.Recovery < 0 && p >= s.Prices[max.Peak] {
+				max.Recovery = i
+			}
+			continue
+		}
+		if depth := 1 - p/s.Prices[peak]; depth > max.Depth {
+			max = Drawdown{Depth: depth, Peak: peak, Trough: i, Recovery: -1}
+		}
+	}
+	return max
+}
+
+// Rolling applies stat over every window of prices, the result is aligned
+// with Prices and NaN until the first full window.
+func (s Series) Rolling(window int, stat func(Series) float64) []float64 {
+	out := make([]float64, len(s.Prices))
+	for i := range out {
+		if window < 1 || i < window-1 {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = stat(Series{Prices: s.Prices[i-window+1 : i+1], Period: s.Period})
+	}
+	return out
+}
+
+func (s Series) RollingVolatility(window int) []float64 {
+	return s.Rolling(window, Series.Volatility)
+}
+
+func (s Series) RollingSharpe(window int, riskFree float64) []float64 {
+	return s.Rolling(window, func(w Series) float64 { return w.Sharpe(riskFree) })
+}
+
+func (s Series) RollingSortino(window int, riskFree float64) []float64 {
+	return s.Rolling(window, func(w Series) float64 { return w.Sortino(riskFree) })
+}
+
+func (s Series) RollingMaxDrawdown(window int) []float64 {
+	return s.Rolling(window, func(w Series) float64 { return w.MaxDrawdown().Depth })
+}
+
+// meanStd returns the mean and sample standard deviation, skipping NaN.
+func meanStd(values []float64) (mean, std float64) {
+	var n int
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			mean += v
+			n++
+		}
+	}
+	if n < 2 {
+		return math.NaN(), math.NaN()
+	}
+	mean /= float64(n)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			std += (v - mean) * (v - mean)
+		}
+	}
+	return mean, math.Sqrt(std / float64(n-1))
+}
@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/esenmx/coincap-go"
+	"github.com/stretchr/testify/require"
+)
+
+var daily = Series{Prices: []float64{100, 110, 99, 121}, Period: coincap.D1.Value()}
+
+func TestSeries_Returns(t *testing.T) {
+	require.InDeltaSlice(t, []float64{0.1, -0.1, 2.0 / 9}, daily.SimpleReturns(), 1e-12)
+	require.InDeltaSlice(t, []float64{math.Log(1.1), math.Log(0.9), math.Log(121.0 / 99)}, daily.LogReturns(), 1e-12)
+	require.Nil(t, Series{Prices: []float64{1}}.SimpleReturns())
+	require.Equal(t, 365.0, daily.PeriodsPerYear())
+	require.Equal(t, 365.0*24, Series{Period: coincap.H1.Value()}.PeriodsPerYear())
+}
+
+func TestSeries_Risk(t *testing.T) {
+	require.InDelta(t, 2.970241, daily.Volatility(), 1e-6)
+	require.InDelta(t, 8.699821, daily.Sharpe(0), 1e-6)
+	require.InDelta(t, 8.684120, daily.Sharpe(0.05), 1e-6)
+	require.InDelta(t, 24.511692, daily.Sortino(0), 1e-6)
+
+	hourly := Series{Prices: daily.Prices, Period: time.Hour}
+	require.InDelta(t, daily.Volatility()*math.Sqrt(24), hourly.Volatility(), 1e-9)
+
+	unset := Series{Prices: daily.Prices}
+	require.InDelta(t, daily.Volatility()/math.Sqrt(365), unset.Volatility(), 1e-9)
+	require.InDelta(t, daily.Sharpe(0)/math.Sqrt(365), unset.Sharpe(0), 1e-9)
+	require.Equal(t, unset.Sharpe(0), unset.Sharpe(0.05))
+	require.InDelta(t, daily.Sortino(0)/math.Sqrt(365), unset.Sortino(0), 1e-9)
+
+	withGap := Series{Prices: []float64{100, 110, math.NaN(), 99, 121}, Period: coincap.D1.Value()}
+	require.InDelta(t, Series{Prices: []float64{100, 110, 110 * 121.0 / 99}, Period: coincap.D1.Value()}.Volatility(), withGap.Volatility(), 1e-12)
+}
+
+func TestSeries_MaxDrawdown(t *testing.T) {
+	dd := daily.MaxDrawdown()
+	require.InDelta(t, 0.1, dd.Depth, 1e-12)
+	require.Equal(t, Drawdown{Depth: dd.Depth, Peak: 1, Trough: 2, Recovery: 3}, dd)
+
+	s := Series{Prices: []float64{10, 8, 10, 12, 6, math.NaN(), 9}}
+	require.Equal(t, Drawdown{Depth: 0.5, Peak: 3, Trough: 4, Recovery: -1}, s.MaxDrawdown())
+	require.Equal(t, Drawdown{Peak: -1, Trough: -1, Recovery: -1}, Series{Prices: []float64{1, 2, 3}}.MaxDrawdown())
+}
+
+func TestSeries_Rolling(t *testing.T) {
+	s := Series{Prices: []float64{10, 8, 10, 12, 6}}
+	dd := s.RollingMaxDrawdown(3)
+	require.True(t, math.IsNaN(dd[0]) && math.IsNaN(dd[1]))
+	require.InDeltaSlice(t, []float64{0.2, 0, 0.5}, dd[2:], 1e-12)
+
+	vol := daily.RollingVolatility(3)
+	require.Len(t, vol, 4)
+	require.InDelta(t, Series{Prices: daily.Prices[1:], Period: daily.Period}.Volatility(), vol[3], 1e-12)
+	require.Len(t, daily.RollingSharpe(3, 0), 4)
+	require.Len(t, daily.RollingSortino(3, 0), 4)
+}
+
+func TestFromHistory(t *testing.T) {
+	b, err := os.ReadFile("../mock/asset_history.json")
+	require.NoError(t, err)
+	var data coincap.AssetHistoriesData
+	require.NoError(t, json.Unmarshal(b, &data))
+	s := FromHistory(data.Data, coincap.M30)
+	require.Len(t, s.Prices, len(data.Data))
+	require.Equal(t, data.Data[0].PriceUsd, s.Prices[0])
+	require.Equal(t, 365.0*48, s.PeriodsPerYear())
+	require.False(t, math.IsNaN(s.Volatility()))
+}
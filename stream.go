@@ -0,0 +1,266 @@
+package coincap
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const wsUrl = "wss://ws.coincap.io"
+
+var errResubscribe = errors.New("resubscribe")
+
+type StreamOption func(*streamConfig)
+
+type streamConfig struct {
+	url         string
+	dialer      *websocket.Dialer
+	header      http.Header
+	buffer      int
+	readTimeout time.Duration
+	backoff     *Backoff
+	events      StreamEventCallback
+	backfill    Api
+	recorder    *recorder
+	replay      *replay
+}
+
+func newStreamConfig(options []StreamOption) streamConfig {
+	cfg := streamConfig{url: wsUrl, dialer: websocket.DefaultDialer, buffer: 256}
+	for _, option := range options {
+		option(&cfg)
+	}
+	return cfg
+}
+
+func WithStreamUrl(u string) StreamOption         { return func(c *streamConfig) { c.url = u } }
+func WithDialer(d *websocket.Dialer) StreamOption { return func(c *streamConfig) { c.dialer = d } }
+func WithStreamHeader(h http.Header) StreamOption { return func(c *streamConfig) { c.header = h } }
+func WithStreamBuffer(size int) StreamOption      { return func(c *streamConfig) { c.buffer = size } }
+func WithStreamEvents(cb StreamEventCallback) StreamOption {
+	return func(c *streamConfig) { c.events = cb }
+}
+
+// WithReadTimeout treats a connection without messages for d as dropped.
+func WithReadTimeout(d time.Duration) StreamOption {
+	return func(c *streamConfig) { c.readTimeout = d }
+}
+
+// WithReconnect redials failed connections instead of returning from Run.
+// Messages that fail to decode are then skipped and reported as a
+// MessageSkipped event rather than ending the stream.
+func WithReconnect(b Backoff) StreamOption { return func(c *streamConfig) { c.backoff = &b } }
+
+// WithPriceBackfill fills a PriceStream outage with M1 GetAssetHistory prices
+// once reconnected. Ignored by other streams and for AllAssets.
+func WithPriceBackfill(api Api) StreamOption { return func(c *streamConfig) { c.backfill = api } }
+
+type Backoff struct {
+	Min        time.Duration // delay before the first retry, DefaultBackoff.Min when zero
+	Max        time.Duration // optional, delay cap. uncapped when zero
+	Factor     float64       // multiplier per attempt, 2 when zero
+	MaxRetries int           // optional, retries before giving up. unlimited when zero
+}
+
+var DefaultBackoff = Backoff{Min: time.Second, Max: time.Minute, Factor: 2}
+
+func (b Backoff) Delay(attempt int) time.Duration {
+	factor := b.Factor
+	if factor == 0 {
+		factor = 2
+	}
+	min := b.Min
+	if min <= 0 {
+		min = DefaultBackoff.Min
+	}
+	d := float64(min) * math.Pow(factor, float64(attempt-1))
+	if b.Max > 0 && d > float64(b.Max) {
+		return b.Max
+	}
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+type StreamEventKind int
+
+const (
+	Disconnected StreamEventKind = iota
+	Reconnected
+	BackfillFailed
+	MessageSkipped
+	RecordFailed
+)
+
+func (k StreamEventKind) String() string {
+	switch k {
+	case Disconnected:
+		return "disconnected"
+	case Reconnected:
+		return "reconnected"
+	case BackfillFailed:
+		return "backfill failed"
+	case MessageSkipped:
+		return "message skipped"
+	case RecordFailed:
+		return "record failed"
+	default:
+		return ""
+	}
+}
+
+type StreamEvent struct {
+	Kind         StreamEventKind
+	Err          error     // cause of the disconnect, the backfill failure, the skipped message or the failed write
+	Disconnected time.Time // start of the outage
+	Reconnected  time.Time // end of the outage, zero for Disconnected
+	Attempts     int       // dials it took to reconnect
+	Message      []byte    // raw message that failed to decode or to be recorded, for MessageSkipped and RecordFailed
+}
+
+// Outage is the window in which messages were missed.
+func (e StreamEvent) Outage() time.Duration {
+	if e.Reconnected.IsZero() {
+		return 0
+	}
+	return e.Reconnected.Sub(e.Disconnected)
+}
+
+// StreamEventCallback is called synchronously from the stream goroutine.
+type StreamEventCallback func(StreamEvent)
+
+// handlerError marks failures of the message handler, reconnecting won't fix them.
+type handlerError struct{ err error }
+
+func (e handlerError) Error() string { return e.err.Error() }
+
+type backfillFunc func(ctx context.Context, from, to time.Time) error
+
+// run keeps a session on path alive, reconnecting according to the backoff policy.
+func (cfg streamConfig) run(ctx context.Context, path func() (string, error), restart <-chan struct{}, backfill backfillFunc, handle func(msg []byte, received time.Time) error) error {
+	var down time.Time
+	var cause error
+	connected, attempts := false, 0
+	onConnect := func() {
+		if !down.IsZero() {
+			up := time.Now()
+			cfg.emitEvent(StreamEvent{Kind: Reconnected, Err: cause, Disconnected: down, Reconnected: up, Attempts: attempts})
+			if backfill != nil {
+				if err := backfill(ctx, down, up); err != nil && ctx.Err() == nil {
+					cfg.emitEvent(StreamEvent{Kind: BackfillFailed, Err: err, Disconnected: down, Reconnected: up})
+				}
+			}
+		}
+		connected, down, cause, attempts = true, time.Time{}, nil, 0
+	}
+	if cfg.backoff != nil {
+		strict := handle
+		handle = func(msg []byte, received time.Time) error {
+			err := strict(msg, received)
+			if err != nil && ctx.Err() == nil {
+				cfg.emitEvent(StreamEvent{Kind: MessageSkipped, Err: err, Message: msg})
+				return nil
+			}
+			return err
+		}
+	}
+	for {
+		p, err := path()
+		if err != nil {
+			return err
+		}
+		if cfg.replay != nil {
+			err = cfg.replay.session(ctx, p, onConnect, handle)
+		} else {
+			err = cfg.session(ctx, p, restart, onConnect, handle)
+		}
+		var he handlerError
+		switch {
+		case err == errResubscribe:
+			continue
+		case err == errReplayDone:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &he):
+			return he.err
+		case cfg.backoff == nil:
+			return err
+		}
+		if connected && down.IsZero() {
+			down, cause = time.Now(), err
+			cfg.emitEvent(StreamEvent{Kind: Disconnected, Err: err, Disconnected: down})
+		}
+		attempts++
+		if cfg.backoff.MaxRetries > 0 && attempts > cfg.backoff.MaxRetries {
+			return err
+		}
+		select {
+		case <-time.After(cfg.backoff.Delay(attempts)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (cfg streamConfig) emitEvent(e StreamEvent) {
+	if cfg.events != nil {
+		cfg.events(e)
+	}
+}
+
+// session dials path and passes every message to handle until ctx is done,
+// restart is signalled (errResubscribe) or the connection fails.
+func (cfg streamConfig) session(ctx context.Context, path string, restart <-chan struct{}, onConnect func(), handle func(msg []byte, received time.Time) error) error {
+	conn, _, err := cfg.dialer.DialContext(ctx, cfg.url+path, cfg.header)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	reason := make(chan error, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			reason <- ctx.Err()
+		case <-restart:
+			reason <- errResubscribe
+		case <-done:
+		}
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		_ = conn.Close()
+	}()
+	onConnect()
+	for {
+		if cfg.readTimeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(cfg.readTimeout))
+		}
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case r := <-reason:
+				return r
+			default:
+				return err
+			}
+		}
+		received := time.Now()
+		if cfg.recorder != nil {
+			if err := cfg.recorder.record(path, msg, received); err != nil {
+				cfg.emitEvent(StreamEvent{Kind: RecordFailed, Err: err, Message: msg})
+			}
+		}
+		if err := handle(msg, received); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return handlerError{err}
+		}
+	}
+}
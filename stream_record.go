@@ -0,0 +1,119 @@
+package coincap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errReplayDone = errors.New("replay done")
+
+// RecordedMessage is one line of a stream recording.
+type RecordedMessage struct {
+	Received time.Time       `json:"received"`
+	Path     string          `json:"path"`
+	Message  json.RawMessage `json:"message,omitempty"`
+	Text     string          `json:"text,omitempty"` // message that is not valid JSON, Message is then empty
+}
+
+func (m RecordedMessage) payload() []byte {
+	if len(m.Message) == 0 {
+		return []byte(m.Text)
+	}
+	return m.Message
+}
+
+// WithRecorder writes every raw message with its receive time to w as NDJSON.
+// The same writer may be shared between streams. Failed writes are reported
+// as a RecordFailed event and never stop the stream.
+func WithRecorder(w io.Writer) StreamOption {
+	return func(c *streamConfig) { c.recorder = &recorder{enc: json.NewEncoder(w)} }
+}
+
+// WithReplay feeds a recording made with WithRecorder through the stream
+// instead of dialing CoinCap. speed scales the recorded gaps, 1 replays in
+// real time, 10 ten times faster, zero without any delay. Run returns nil once
+// the recording is exhausted. Messages recorded by other streams of a shared
+// recording are skipped, paths are compared without their query so prices
+// replay whatever assets were subscribed.
+func WithReplay(r io.Reader, speed float64) StreamOption {
+	return func(c *streamConfig) { c.replay = &replay{dec: json.NewDecoder(bufio.NewReader(r)), speed: speed} }
+}
+
+type recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (r *recorder) record(path string, msg []byte, received time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := RecordedMessage{Received: received, Path: path}
+	if json.Valid(msg) {
+		m.Message = msg
+	} else {
+		m.Text = string(msg)
+	}
+	return r.enc.Encode(m)
+}
+
+type replay struct {
+	dec   *json.Decoder
+	speed float64
+	last  time.Time
+}
+
+// session replays the recorded messages of path. Resubscribing has no effect
+// on a recording, so restart is not watched.
+func (r *replay) session(ctx context.Context, path string, onConnect func(), handle func(msg []byte, received time.Time) error) error {
+	onConnect()
+	for {
+		var m RecordedMessage
+		if err := r.dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return errReplayDone
+			}
+			return handlerError{err}
+		}
+		if stripQuery(m.Path) != stripQuery(path) {
+			continue
+		}
+		if err := r.wait(ctx, m.Received); err != nil {
+			return err
+		}
+		if err := handle(m.payload(), m.Received); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return handlerError{err}
+		}
+	}
+}
+
+func (r *replay) wait(ctx context.Context, received time.Time) error {
+	last := r.last
+	r.last = received
+	if r.speed <= 0 || last.IsZero() || !received.After(last) {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(time.Duration(float64(received.Sub(last)) / r.speed))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func stripQuery(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
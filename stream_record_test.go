@@ -0,0 +1,141 @@
+package coincap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream_RecordReplay(t *testing.T) {
+	_, option := wsServer(t, func(conn *websocket.Conn, uri string) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"6929.82","ethereum":"404.97"}`))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"6930.01"}`))
+		drain(conn)
+	})
+
+	var recording bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	live := NewPriceStream([]string{"bitcoin", "ethereum"}, option, WithRecorder(&recording))
+	go func() { _ = live.Run(ctx) }()
+	var recorded []PriceUpdate
+	for i := 0; i < 3; i++ {
+		recorded = append(recorded, <-live.Updates())
+	}
+	cancel()
+	for range live.Updates() {
+	}
+
+	lines := strings.Split(strings.TrimSpace(recording.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[1], `"path":"/prices?assets=bitcoin,ethereum","message":{"bitcoin":"6930.01"}`)
+
+	replayed := NewPriceStream([]string{"bitcoin"}, WithReplay(strings.NewReader(recording.String()), 0))
+	require.NoError(t, replayed.Run(context.Background()))
+	var updates []PriceUpdate
+	for u := range replayed.Updates() {
+		updates = append(updates, u)
+	}
+	require.Len(t, updates, 3)
+	for i := range updates {
+		require.True(t, recorded[i].Received.Equal(updates[i].Received))
+		recorded[i].Received, updates[i].Received = time.Time{}, time.Time{}
+	}
+	require.Equal(t, recorded, updates)
+}
+
+func TestStream_RecordNonJson(t *testing.T) {
+	_, option := wsServer(t, func(conn *websocket.Conn, uri string) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`not json`))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"2"}`))
+		drain(conn)
+	})
+	var recording bytes.Buffer
+	events := make(chan StreamEvent, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	s := NewPriceStream([]string{"bitcoin"}, option, WithRecorder(&recording), WithReconnect(DefaultBackoff),
+		WithStreamEvents(func(e StreamEvent) { events <- e }))
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	require.Equal(t, 2.0, (<-s.Updates()).PriceUsd)
+	skipped := <-events
+	require.Equal(t, MessageSkipped, skipped.Kind)
+	require.Equal(t, "not json", string(skipped.Message))
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+
+	lines := strings.Split(strings.TrimSpace(recording.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"text":"not json"`)
+	require.NotContains(t, lines[0], `"message"`)
+
+	events = make(chan StreamEvent, 4)
+	replayed := NewPriceStream([]string{"bitcoin"}, WithReplay(strings.NewReader(recording.String()), 0),
+		WithReconnect(DefaultBackoff), WithStreamEvents(func(e StreamEvent) { events <- e }))
+	require.NoError(t, replayed.Run(context.Background()))
+	require.Equal(t, "not json", string((<-events).Message))
+	require.Equal(t, 2.0, (<-replayed.Updates()).PriceUsd)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestStream_RecordFailed(t *testing.T) {
+	_, option := wsServer(t, func(conn *websocket.Conn, uri string) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"1"}`))
+		drain(conn)
+	})
+	events := make(chan StreamEvent, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	s := NewPriceStream([]string{"bitcoin"}, option, WithRecorder(failingWriter{}),
+		WithStreamEvents(func(e StreamEvent) { events <- e }))
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	require.Equal(t, 1.0, (<-s.Updates()).PriceUsd)
+	failed := <-events
+	require.Equal(t, RecordFailed, failed.Kind)
+	require.EqualError(t, failed.Err, "disk full")
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestStream_ReplaySpeed(t *testing.T) {
+	recording := `{"received":"2021-07-26T11:00:00Z","path":"/trades/binance","message":{"exchange":"binance","base":"bitcoin","quote":"tether","price":1,"timestamp":1627297200000}}
+{"received":"2021-07-26T11:00:02Z","path":"/trades/binance","message":{"exchange":"binance","base":"bitcoin","quote":"tether","price":2,"timestamp":1627297202000}}
+`
+	s := NewTradeStream("binance", TradeFilter{}, WithReplay(strings.NewReader(recording), 20), WithStreamBuffer(2))
+	start := time.Now()
+	require.NoError(t, s.Run(context.Background()))
+	elapsed := time.Since(start)
+	require.True(t, elapsed >= time.Millisecond*100 && elapsed < time.Second, elapsed)
+	require.Len(t, s.Trades(), 2)
+	require.Equal(t, 1.0, (<-s.Trades()).Price)
+	require.Equal(t, 2.0, (<-s.Trades()).Price)
+}
+
+func TestStream_ReplayShared(t *testing.T) {
+	recording := `{"received":"2021-07-26T11:00:00Z","path":"/trades/binance","message":{"exchange":"binance","base":"bitcoin","quote":"tether","price":1,"timestamp":1627297200000}}
+{"received":"2021-07-26T11:00:01Z","path":"/prices?assets=bitcoin","message":{"bitcoin":"6929.82"}}
+{"received":"2021-07-26T11:00:02Z","path":"/trades/kraken","message":{"exchange":"kraken","base":"bitcoin","quote":"tether","price":2,"timestamp":1627297202000}}
+`
+	prices := NewPriceStream([]string{"bitcoin", "ethereum"}, WithReplay(strings.NewReader(recording), 0))
+	require.NoError(t, prices.Run(context.Background()))
+	require.Len(t, prices.Updates(), 1)
+	require.Equal(t, 6929.82, (<-prices.Updates()).PriceUsd)
+
+	trades := NewTradeStream("kraken", TradeFilter{}, WithReplay(strings.NewReader(recording), 0))
+	require.NoError(t, trades.Run(context.Background()))
+	require.Len(t, trades.Trades(), 1)
+	require.Equal(t, "kraken", (<-trades.Trades()).Exchange)
+}
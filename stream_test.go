@@ -0,0 +1,136 @@
+package coincap
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Min: time.Second, Max: time.Second * 5}
+	require.Equal(t, time.Second, b.Delay(1))
+	require.Equal(t, time.Second*2, b.Delay(2))
+	require.Equal(t, time.Second*4, b.Delay(3))
+	require.Equal(t, time.Second*5, b.Delay(4))
+	require.Equal(t, time.Second*5, b.Delay(1000))
+
+	uncapped := Backoff{Min: time.Second}
+	require.Equal(t, time.Second, uncapped.Delay(1))
+	require.Equal(t, time.Second*8, uncapped.Delay(4))
+	require.Equal(t, time.Duration(math.MaxInt64), uncapped.Delay(1000))
+
+	require.Equal(t, DefaultBackoff.Min, Backoff{}.Delay(1))
+	require.Equal(t, DefaultBackoff.Min*2, Backoff{}.Delay(2))
+	require.True(t, Backoff{Min: -time.Second}.Delay(1) > 0)
+}
+
+func TestStream_Reconnect(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	_, option := wsServer(t, func(conn *websocket.Conn, uri string) {
+		mu.Lock()
+		connections++
+		n := connections
+		mu.Unlock()
+		if n == 1 {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"1"}`))
+			return // drops the connection
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"2"}`))
+		drain(conn)
+	})
+
+	events := make(chan StreamEvent, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	s := NewPriceStream([]string{"bitcoin"}, option,
+		WithReconnect(Backoff{Min: time.Millisecond, Max: time.Millisecond * 10}),
+		WithStreamEvents(func(e StreamEvent) { events <- e }))
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	require.Equal(t, 1.0, (<-s.Updates()).PriceUsd)
+	require.Equal(t, 2.0, (<-s.Updates()).PriceUsd)
+	disconnected := <-events
+	require.Equal(t, Disconnected, disconnected.Kind)
+	require.Error(t, disconnected.Err)
+	reconnected := <-events
+	require.Equal(t, Reconnected, reconnected.Kind)
+	require.Equal(t, disconnected.Disconnected, reconnected.Disconnected)
+	require.Equal(t, 1, reconnected.Attempts)
+	require.True(t, reconnected.Outage() > 0)
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestStream_SkipsBadMessages(t *testing.T) {
+	_, option := wsServer(t, func(conn *websocket.Conn, uri string) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"broken"}`))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"bitcoin":"2"}`))
+		drain(conn)
+	})
+	events := make(chan StreamEvent, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	s := NewPriceStream([]string{"bitcoin"}, option, WithReconnect(DefaultBackoff),
+		WithStreamEvents(func(e StreamEvent) { events <- e }))
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	require.Equal(t, 2.0, (<-s.Updates()).PriceUsd)
+	skipped := <-events
+	require.Equal(t, MessageSkipped, skipped.Kind)
+	require.Error(t, skipped.Err)
+	require.Equal(t, `{"bitcoin":"broken"}`, string(skipped.Message))
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestStream_MaxRetries(t *testing.T) {
+	s := NewTradeStream("binance", TradeFilter{}, WithStreamUrl("ws://127.0.0.1:1"),
+		WithReconnect(Backoff{Min: time.Millisecond, Max: time.Millisecond, MaxRetries: 2}))
+	require.Error(t, s.Run(context.Background()))
+}
+
+type historyStub struct {
+	Api
+	params []GetAssetHistoryParams
+}
+
+func (h *historyStub) GetAssetHistory(p GetAssetHistoryParams) (AssetHistoriesData, error) {
+	h.params = append(h.params, p)
+	if p.Id == "broken" {
+		return AssetHistoriesData{}, errors.New("broken")
+	}
+	var data AssetHistoriesData
+	for t := p.Start; t.Before(p.End); t = t.Add(time.Minute) {
+		data.Data = append(data.Data, AssetHistory{PriceUsd: 10, Time: t.UnixMilli(), Date: t})
+	}
+	return data, nil
+}
+
+func TestPriceStream_Backfill(t *testing.T) {
+	stub := &historyStub{}
+	s := NewPriceStream([]string{"bitcoin", AllAssets}, WithPriceBackfill(stub))
+	from := time.Date(2021, 7, 26, 11, 0, 30, 0, time.UTC)
+	require.NoError(t, s.backfill(context.Background(), from, from.Add(time.Minute*3)))
+	require.Len(t, stub.params, 1)
+	require.Equal(t, from.Add(-time.Second*30), stub.params[0].Start)
+	require.Len(t, s.Updates(), 4)
+	u := <-s.Updates()
+	require.Equal(t, PriceUpdate{Id: "bitcoin", PriceUsd: 10, Received: from.Add(-time.Second * 30), Backfill: true}, u)
+
+	require.NoError(t, s.backfill(context.Background(), from, from.Add(time.Second)))
+	require.Len(t, stub.params, 1)
+
+	s.Subscribe("broken")
+	require.Error(t, s.backfill(context.Background(), from, from.Add(time.Minute*3)))
+}
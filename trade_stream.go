@@ -0,0 +1,109 @@
+package coincap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var StreamingUnsupportedError = errors.New("exchange does not support streaming")
+
+type TradeFilter struct {
+	BaseId  string // optional, e.g. bitcoin
+	QuoteId string // optional, e.g. tether
+}
+
+func (f TradeFilter) Match(t Trade) bool {
+	return (len(f.BaseId) == 0 || f.BaseId == t.Base) && (len(f.QuoteId) == 0 || f.QuoteId == t.Quote)
+}
+
+// TradeStream consumes the trades socket of an exchange, e.g. wss://ws.coincap.io/trades/binance
+type TradeStream struct {
+	config   streamConfig
+	exchange string
+	mu       sync.Mutex
+	filter   TradeFilter
+	trades   chan Trade
+}
+
+func NewTradeStream(exchangeId string, filter TradeFilter, options ...StreamOption) *TradeStream {
+	cfg := newStreamConfig(options)
+	return &TradeStream{config: cfg, exchange: exchangeId, filter: filter, trades: make(chan Trade, cfg.buffer)}
+}
+
+// TradeStream fails with StreamingUnsupportedError unless the exchange has a socket.
+func (e Exchange) TradeStream(filter TradeFilter, options ...StreamOption) (*TradeStream, error) {
+	if !e.Socket {
+		return nil, fmt.Errorf("%w: %s", StreamingUnsupportedError, e.ExchangeId)
+	}
+	return NewTradeStream(e.ExchangeId, filter, options...), nil
+}
+
+// Trades is closed once Run returns.
+func (s *TradeStream) Trades() <-chan Trade { return s.trades }
+
+func (s *TradeStream) Exchange() string { return s.exchange }
+
+func (s *TradeStream) Filter() TradeFilter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filter
+}
+
+// SetFilter applies to trades received from now on, without reconnecting.
+func (s *TradeStream) SetFilter(filter TradeFilter) {
+	s.mu.Lock()
+	s.filter = filter
+	s.mu.Unlock()
+}
+
+// Run streams until ctx is done or the connection fails, see WithReconnect.
+// It may be called once.
+func (s *TradeStream) Run(ctx context.Context) error {
+	defer close(s.trades)
+	return s.config.run(ctx, s.path, nil, nil, func(msg []byte, received time.Time) error {
+		return s.emit(ctx, msg)
+	})
+}
+
+func (s *TradeStream) path() (string, error) {
+	if len(s.exchange) == 0 {
+		return "", MissingParameterError
+	}
+	return "/trades/" + s.exchange, nil
+}
+
+func (s *TradeStream) emit(ctx context.Context, msg []byte) error {
+	trades, err := decodeTrades(msg)
+	if err != nil {
+		return err
+	}
+	filter := s.Filter()
+	for _, t := range trades {
+		if !filter.Match(t) {
+			continue
+		}
+		select {
+		case s.trades <- t:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// decodeTrades parses a single trade object or an array of them.
+func decodeTrades(msg []byte) ([]Trade, error) {
+	if len(msg) > 0 && msg[0] == '[' {
+		var trades []Trade
+		return trades, json.Unmarshal(msg, &trades)
+	}
+	var t Trade
+	if err := json.Unmarshal(msg, &t); err != nil {
+		return nil, err
+	}
+	return []Trade{t}, nil
+}
@@ -0,0 +1,60 @@
+package coincap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTradeStream(t *testing.T) {
+	uris := make(chan string, 1)
+	_, option := wsServer(t, func(conn *websocket.Conn, uri string) {
+		uris <- uri
+		for _, msg := range []string{
+			`{"exchange":"binance","base":"ethereum","quote":"tether","direction":"buy","price":404.1,"volume":1.5,"timestamp":1533581097000,"priceUsd":404.5}`,
+			`{"exchange":"binance","base":"bitcoin","quote":"tether","direction":"sell","price":6927.17,"volume":0.012,"timestamp":1533581097587,"priceUsd":6927.17}`,
+			`[{"exchange":"binance","base":"bitcoin","quote":"euro","direction":"buy","price":6000,"volume":1,"timestamp":1533581098000,"priceUsd":6900}]`,
+		} {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+		drain(conn)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	s, err := Exchange{ExchangeId: "binance", Socket: true}.TradeStream(TradeFilter{BaseId: "bitcoin"}, option)
+	require.NoError(t, err)
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	require.Equal(t, "/trades/binance", <-uris)
+	require.Equal(t, Trade{
+		Exchange:  "binance",
+		Base:      "bitcoin",
+		Quote:     "tether",
+		Direction: "sell",
+		Price:     6927.17,
+		Volume:    0.012,
+		Timestamp: 1533581097587,
+		PriceUsd:  6927.17,
+	}, <-s.Trades())
+	require.Equal(t, "euro", (<-s.Trades()).Quote)
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestExchange_TradeStream(t *testing.T) {
+	_, err := Exchange{ExchangeId: "bitso"}.TradeStream(TradeFilter{})
+	require.ErrorIs(t, err, StreamingUnsupportedError)
+}
+
+func TestTradeFilter_Match(t *testing.T) {
+	trade := Trade{Base: "bitcoin", Quote: "tether"}
+	require.True(t, TradeFilter{}.Match(trade))
+	require.True(t, TradeFilter{BaseId: "bitcoin", QuoteId: "tether"}.Match(trade))
+	require.False(t, TradeFilter{QuoteId: "usd-coin"}.Match(trade))
+}
@@ -0,0 +1,191 @@
+package coincap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var InvalidModelError = errors.New("invalid model")
+
+type ValidationError struct {
+	Model    string   // e.g. "Asset"
+	Key      string   // identifies the row, e.g. asset id or candle period
+	Problems []string // e.g. "negative supply"
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s %s: %s", InvalidModelError, e.Model, e.Key, strings.Join(e.Problems, ", "))
+}
+
+func (e *ValidationError) Is(target error) bool { return target == InvalidModelError }
+
+type ValidationMode int
+
+const (
+	ValidationIgnore ValidationMode = iota // keep every row, the default
+	ValidationFilter                       // drop invalid rows from list responses
+	ValidationReject                       // fail the request on the first invalid row, leaving the rows untouched
+)
+
+type ValidationCallback func(url string, dropped []*ValidationError)
+
+// WithValidation validates decoded Asset, AssetHistory, AssetMarket, Market,
+// Exchange and Candle rows. Single object responses fail in both modes since
+// there is nothing to filter them down to.
+func WithValidation(mode ValidationMode, callback ValidationCallback) Option {
+	return func(c *Client) {
+		c.validationMode = mode
+		c.validationCallback = callback
+	}
+}
+
+type validator interface {
+	Validate() error
+}
+
+type problems []string
+
+func (p *problems) check(failed bool, problem string) {
+	if failed {
+		*p = append(*p, problem)
+	}
+}
+
+func (p problems) err(model, key string) error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Model: model, Key: key, Problems: p}
+}
+
+func invalidFloat(v float64) bool { return math.IsNaN(v) || math.IsInf(v, 0) }
+
+func (a Asset) Validate() error {
+	var p problems
+	p.check(len(a.Id) == 0, "missing id")
+	p.check(a.Rank < 0, "negative rank")
+	p.check(a.Supply < 0 || invalidFloat(a.Supply), "invalid supply")
+	p.check(a.MaxSupply != nil && (*a.MaxSupply < 0 || invalidFloat(*a.MaxSupply)), "invalid max supply")
+	p.check(a.MarketCapUsd < 0 || invalidFloat(a.MarketCapUsd), "invalid market cap")
+	p.check(a.VolumeUsd24Hr < 0 || invalidFloat(a.VolumeUsd24Hr), "invalid volume")
+	p.check(a.PriceUsd < 0 || invalidFloat(a.PriceUsd), "invalid price")
+	p.check(a.Vwap24Hr < 0 || invalidFloat(a.Vwap24Hr), "invalid vwap")
+	p.check(invalidFloat(a.ChangePercent24Hr), "invalid change percent")
+	return p.err("Asset", a.Id)
+}
+
+func (h AssetHistory) Validate() error {
+	var p problems
+	p.check(h.PriceUsd <= 0 || invalidFloat(h.PriceUsd), "non-positive price")
+	p.check(h.CirculatingSupply < 0 || invalidFloat(h.CirculatingSupply), "invalid circulating supply")
+	p.check(h.Time <= 0, "missing time")
+	return p.err("AssetHistory", fmt.Sprint(h.Time))
+}
+
+func (m AssetMarket) Validate() error {
+	var p problems
+	p.check(len(m.ExchangeId) == 0, "missing exchange id")
+	p.check(len(m.BaseId) == 0, "missing base id")
+	p.check(len(m.QuoteId) == 0, "missing quote id")
+	p.check(m.PriceUsd <= 0 || invalidFloat(m.PriceUsd), "non-positive price")
+	p.check(m.VolumeUsd24Hr < 0 || invalidFloat(m.VolumeUsd24Hr), "invalid volume")
+	p.check(m.VolumePercent < 0 || m.VolumePercent > 100, "volume percent out of range")
+	return p.err("AssetMarket", fmt.Sprintf("%s:%s/%s", m.ExchangeId, m.BaseId, m.QuoteId))
+}
+
+func (m Market) Validate() error {
+	var p problems
+	p.check(len(m.ExchangeId) == 0, "missing exchange id")
+	p.check(len(m.BaseId) == 0, "missing base id")
+	p.check(len(m.QuoteId) == 0, "missing quote id")
+	p.check(m.PriceQuote <= 0 || invalidFloat(m.PriceQuote), "non-positive quote price")
+	p.check(m.PriceUsd <= 0 || invalidFloat(m.PriceUsd), "non-positive price")
+	p.check(m.VolumeUsd24Hr < 0 || invalidFloat(m.VolumeUsd24Hr), "invalid volume")
+	p.check(m.PercentExchangeVolume < 0 || m.PercentExchangeVolume > 100, "percent exchange volume out of range")
+	p.check(m.TradesCount24Hr < 0, "negative trades count")
+	return p.err("Market", fmt.Sprintf("%s:%s/%s", m.ExchangeId, m.BaseId, m.QuoteId))
+}
+
+func (e Exchange) Validate() error {
+	var p problems
+	p.check(len(e.ExchangeId) == 0, "missing exchange id")
+	p.check(e.Rank < 0, "negative rank")
+	p.check(e.PercentTotalVolume < 0 || e.PercentTotalVolume > 100, "percent total volume out of range")
+	p.check(e.VolumeUsd < 0 || invalidFloat(e.VolumeUsd), "invalid volume")
+	p.check(e.TradingPairs < 0, "negative trading pairs")
+	return p.err("Exchange", e.ExchangeId)
+}
+
+func (c Candle) Validate() error {
+	var p problems
+	p.check(c.Open <= 0 || c.High <= 0 || c.Low <= 0 || c.Close <= 0, "non-positive price")
+	p.check(c.High < c.Low, "high below low")
+	p.check(c.Open > c.High || c.Open < c.Low, "open outside high/low")
+	p.check(c.Close > c.High || c.Close < c.Low, "close outside high/low")
+	p.check(c.Volume < 0 || invalidFloat(c.Volume), "invalid volume")
+	p.check(c.Period <= 0, "missing period")
+	return p.err("Candle", fmt.Sprint(c.Period))
+}
+
+func (c *Client) validate(url string, ptr interface{}) error {
+	var dropped []*ValidationError
+	filter := c.validationMode == ValidationFilter
+	switch data := ptr.(type) {
+	case *AssetsData:
+		dropped = validateRows(&data.Data, filter)
+	case *AssetHistoriesData:
+		dropped = validateRows(&data.Data, filter)
+	case *AssetMarketsData:
+		dropped = validateRows(&data.Data, filter)
+	case *MarketsData:
+		dropped = validateRows(&data.Data, filter)
+	case *ExchangesData:
+		dropped = validateRows(&data.Data, filter)
+	case *CandlesData:
+		dropped = validateRows(&data.Data, filter)
+	case *AssetData:
+		dropped = validateOne(data.Asset)
+	case *ExchangeData:
+		dropped = validateOne(data.Data)
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+	if c.validationCallback != nil {
+		c.validationCallback(url, dropped)
+	}
+	switch ptr.(type) {
+	case *AssetData, *ExchangeData:
+		return dropped[0]
+	}
+	if c.validationMode == ValidationReject {
+		return dropped[0]
+	}
+	return nil
+}
+
+// validateRows reports the invalid rows, removing them when filter is set.
+func validateRows[T validator](rows *[]T, filter bool) []*ValidationError {
+	var dropped []*ValidationError
+	var valid []T
+	for _, row := range *rows {
+		if err := row.Validate(); err != nil {
+			dropped = append(dropped, err.(*ValidationError))
+			continue
+		}
+		valid = append(valid, row)
+	}
+	if filter && len(dropped) > 0 {
+		*rows = valid
+	}
+	return dropped
+}
+
+func validateOne(v validator) []*ValidationError {
+	if err := v.Validate(); err != nil {
+		return []*ValidationError{err.(*ValidationError)}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package coincap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_Mocks(t *testing.T) {
+	var assets AssetsData
+	require.NoError(t, unmarshalModel("assets", &assets))
+	var history AssetHistoriesData
+	require.NoError(t, unmarshalModel("asset_history", &history))
+	var assetMarkets AssetMarketsData
+	require.NoError(t, unmarshalModel("asset_markets", &assetMarkets))
+	var markets MarketsData
+	require.NoError(t, unmarshalModel("markets", &markets))
+	var exchanges ExchangesData
+	require.NoError(t, unmarshalModel("exchanges", &exchanges))
+	var candles CandlesData
+	require.NoError(t, unmarshalModel("candles", &candles))
+	for _, rows := range [][]validator{
+		toValidators(assets.Data), toValidators(history.Data), toValidators(assetMarkets.Data), toValidators(markets.Data),
+		toValidators(exchanges.Data), toValidators(candles.Data),
+	} {
+		for _, v := range rows {
+			require.NoError(t, v.Validate())
+		}
+	}
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	negative := -1.0
+	err := Asset{Id: "x", Supply: -1, MaxSupply: &negative}.Validate()
+	require.True(t, errors.Is(err, InvalidModelError))
+	require.Equal(t, []string{"invalid supply", "invalid max supply"}, err.(*ValidationError).Problems)
+
+	err = Candle{Open: 2, High: 1, Low: 3, Close: 2, Period: 1}.Validate()
+	require.Equal(t, []string{"high below low", "open outside high/low", "close outside high/low"}, err.(*ValidationError).Problems)
+
+	require.Error(t, AssetHistory{Time: 1}.Validate())
+	require.Error(t, Market{ExchangeId: "binance", BaseId: "bitcoin", QuoteId: "tether", PriceQuote: 1}.Validate())
+	require.Error(t, Exchange{ExchangeId: "binance", PercentTotalVolume: 101}.Validate())
+	err = AssetMarket{ExchangeId: "binance", BaseId: "bitcoin", QuoteId: "tether", PriceUsd: 1, VolumePercent: 101}.Validate()
+	require.Equal(t, []string{"volume percent out of range"}, err.(*ValidationError).Problems)
+}
+
+func TestClient_Validation(t *testing.T) {
+	body, err := json.Marshal(CandlesData{Data: []Candle{
+		{Open: 1, High: 2, Low: 1, Close: 2, Volume: 1, Period: 1},
+		{Open: 1, High: 1, Low: 2, Close: 1, Volume: 1, Period: 2},
+		{Open: 1, High: 2, Low: 1, Close: 2, Volume: 1, Period: 3},
+	}})
+	require.NoError(t, err)
+
+	var data CandlesData
+	require.NoError(t, bodyClient(body).Do(url, nil, &data))
+	require.Len(t, data.Data, 3)
+
+	var dropped []*ValidationError
+	callback := func(url string, errs []*ValidationError) { dropped = errs }
+	data = CandlesData{}
+	require.NoError(t, bodyClient(body, WithValidation(ValidationFilter, callback)).Do(url, nil, &data))
+	require.Len(t, data.Data, 2)
+	require.Equal(t, []int64{1, 3}, []int64{data.Data[0].Period, data.Data[1].Period})
+	require.Len(t, dropped, 1)
+	require.Equal(t, "2", dropped[0].Key)
+
+	data = CandlesData{}
+	err = bodyClient(body, WithValidation(ValidationReject, nil)).Do(url, nil, &data)
+	require.True(t, errors.Is(err, InvalidModelError))
+	require.Len(t, data.Data, 3)
+	require.Equal(t, []int64{1, 2, 3}, []int64{data.Data[0].Period, data.Data[1].Period, data.Data[2].Period})
+
+	body, err = json.Marshal(AssetMarketsData{Data: []AssetMarket{
+		{ExchangeId: "binance", BaseId: "bitcoin", QuoteId: "tether", PriceUsd: 1},
+		{ExchangeId: "kraken", BaseId: "bitcoin", QuoteId: "tether"},
+	}})
+	require.NoError(t, err)
+	var markets AssetMarketsData
+	require.NoError(t, bodyClient(body, WithValidation(ValidationFilter, callback)).Do(url, nil, &markets))
+	require.Len(t, markets.Data, 1)
+	require.Equal(t, "kraken:bitcoin/tether", dropped[0].Key)
+}
+
+func toValidators[T validator](rows []T) []validator {
+	vs := make([]validator, len(rows))
+	for i, v := range rows {
+		vs[i] = v
+	}
+	return vs
+}